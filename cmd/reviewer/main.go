@@ -1,13 +1,16 @@
 package main
 
 import (
+	"code-reviewer-bot/internal/fakellm"
 	"code-reviewer-bot/internal/models"
 	"code-reviewer-bot/internal/repository"
 	"code-reviewer-bot/internal/service"
+	"code-reviewer-bot/internal/storage"
 	"context"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -22,10 +25,11 @@ import (
 )
 
 var (
-	configPath string
-	repoOwner  string
-	repoName   string
-	prNumber   int
+	configPath      string
+	repoOwner       string
+	repoName        string
+	prNumber        int
+	coverageReports []string
 )
 
 func main() {
@@ -42,6 +46,14 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
+		// The reports live in the CI workspace, not in the clone the review runs on.
+		for _, report := range coverageReports {
+			path, err := filepath.Abs(report)
+			if err != nil {
+				log.Fatalf("Invalid coverage report path %s: %v", report, err)
+			}
+			cfg.Review.Coverage.Reports = append(cfg.Review.Coverage.Reports, path)
+		}
 
 		g, err := initGenkit(ctx, cfg)
 		if err != nil {
@@ -57,8 +69,14 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			log.Fatalf("Failed to create VCS client: %v", err)
 		}
+		fillPRDetails(ctx, vcsClient, prDetails)
+
+		store, err := storage.New(&cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to create review store: %v", err)
+		}
 
-		reviewService := service.NewReviewService(vcsClient, g, cfg)
+		reviewService := service.NewReviewService(vcsClient, store, g, cfg)
 		var baseUrl string
 
 		if cfg.VCS.Provider == "Github" {
@@ -83,24 +101,56 @@ func init() {
 	rootCmd.Flags().StringVar(&repoOwner, "repo-owner", "", "Repository owner (overrides env)")
 	rootCmd.Flags().StringVar(&repoName, "repo-name", "", "Repository name (overrides env)")
 	rootCmd.Flags().IntVar(&prNumber, "pr-number", 0, "PR number (overrides env)")
+	rootCmd.Flags().StringSliceVar(&coverageReports, "coverage", nil, "Coverage report (coverage.out, LCOV, Cobertura or JaCoCo XML) to check the PR's added lines against; repeatable")
 }
 
 func Execute() {
 	cobra.CheckErr(rootCmd.Execute())
 }
 
-// initGenkit initializes the Genkit instance and loads the appropriate LLM plugin.
+// initGenkit initializes the Genkit instance, loads the LLM plugin for every
+// provider the configuration refers to and registers the prompt files.
 func initGenkit(ctx context.Context, cfg *config.Config) (*genkit.Genkit, error) {
-	var plugin genkit.Plugin
-	switch cfg.LLM.Provider {
+	var plugins []genkit.Plugin
+	for _, m := range cfg.LLMProviders() {
+		plugin, err := newLLMPlugin(cfg, m.Provider, m.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, plugin)
+	}
+	return genkit.Init(ctx, genkit.WithPlugins(plugins...), genkit.WithPromptDir(cfg.PromptDir))
+}
+
+// newLLMPlugin returns the Genkit plugin for the given provider.
+func newLLMPlugin(cfg *config.Config, provider, apiKey string) (genkit.Plugin, error) {
+	switch provider {
 	case constants.GOOGLEAI:
-		plugin = &googlegenai.GoogleAI{APIKey: cfg.LLM.APIKey}
+		return &googlegenai.GoogleAI{APIKey: apiKey}, nil
 	case constants.OPENAI:
-		plugin = &openai.OpenAI{APIKey: cfg.LLM.APIKey}
+		return &openai.OpenAI{APIKey: apiKey}, nil
+	case constants.FAKE:
+		return fakellm.New(cfg), nil
 	default:
-		return nil, fmt.Errorf("unsupported LLM provider in config: %s", cfg.LLM.Provider)
+		return nil, fmt.Errorf("unsupported LLM provider in config: %s", provider)
+	}
+}
+
+// fillPRDetails completes the details read from the environment with the PR's
+// title, description and URL, and its base branch when no env var set it.
+func fillPRDetails(ctx context.Context, vcsClient repository.VcsRepository, prDetails *models.PRDetails) {
+	pr, err := vcsClient.GetPullRequest(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber)
+	if err != nil {
+		log.Printf("Warning: could not fetch PR details: %v", err)
+		return
+	}
+	prDetails.Title = pr.Title
+	prDetails.Body = pr.Body
+	prDetails.Branch = pr.Branch
+	prDetails.URL = pr.URL
+	if prDetails.BaseBranch == "" {
+		prDetails.BaseBranch = pr.BaseBranch
 	}
-	return genkit.Init(ctx, genkit.WithPlugins(plugin))
 }
 
 // getPRDetailsFromEnv retrieves PR information from environment variables.
@@ -139,9 +189,15 @@ func getPRDetailsFromEnv(provider string, baseUrl string) (*models.PRDetails, er
 		return nil, fmt.Errorf("invalid PR_NUMBER: %w", err)
 	}
 
+	baseBranch := os.Getenv(constants.BASE_REF)
+	if baseBranch == "" {
+		baseBranch = os.Getenv(constants.GITHUB_BASE_REF)
+	}
+
 	return &models.PRDetails{
-		Owner:    parts[0],
-		Repo:     parts[1],
-		PRNumber: prNumber,
+		Owner:      parts[0],
+		Repo:       parts[1],
+		PRNumber:   prNumber,
+		BaseBranch: baseBranch,
 	}, nil
 }
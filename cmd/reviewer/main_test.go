@@ -3,10 +3,14 @@ package main
 import (
 	"code-reviewer-bot/config"
 	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/repository"
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 )
 
 func TestInitGenkit(t *testing.T) {
@@ -38,6 +42,36 @@ func TestInitGenkit(t *testing.T) {
 		assert.NotNil(t, g)
 	})
 
+	t.Run("Success - initializes every provider in the fallback chain", func(t *testing.T) {
+		cfg := &config.Config{
+			LLM: config.LLMConfig{
+				Provider: constants.GOOGLEAI,
+				APIKey:   "fake-googleai-key",
+				Fallbacks: []config.LLMModelConfig{
+					{Provider: constants.OPENAI, ModelName: "openai/gpt-4o-mini", APIKey: "fake-openai-key"},
+				},
+			},
+		}
+
+		g, err := initGenkit(ctx, cfg)
+		assert.NoError(t, err)
+		assert.NotNil(t, g)
+	})
+
+	t.Run("Failure - unsupported fallback provider", func(t *testing.T) {
+		cfg := &config.Config{
+			LLM: config.LLMConfig{
+				Provider:  constants.GOOGLEAI,
+				APIKey:    "fake-googleai-key",
+				Fallbacks: []config.LLMModelConfig{{Provider: "unsupported-provider", ModelName: "x"}},
+			},
+		}
+
+		_, err := initGenkit(ctx, cfg)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported LLM provider")
+	})
+
 	t.Run("Failure - unsupported provider", func(t *testing.T) {
 		cfg := &config.Config{
 			LLM: config.LLMConfig{
@@ -113,3 +147,32 @@ func TestGetPRDetailsFromEnv(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid PR_NUMBER")
 	})
 }
+
+func TestFillPRDetails(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - fills the title and keeps the env base branch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().GetPullRequest(ctx, "owner", "repo", 7).Return(&models.PRDetails{
+			Title: "Add retries", Body: "Fixes #3", Branch: "feature", BaseBranch: "main", URL: "https://example.com/pr/7",
+		}, nil)
+
+		prDetails := &models.PRDetails{Owner: "owner", Repo: "repo", PRNumber: 7, BaseBranch: "release"}
+		fillPRDetails(ctx, mockRepo, prDetails)
+		assert.Equal(t, &models.PRDetails{
+			Owner: "owner", Repo: "repo", PRNumber: 7,
+			Title: "Add retries", Body: "Fixes #3", Branch: "feature", BaseBranch: "release", URL: "https://example.com/pr/7",
+		}, prDetails)
+	})
+
+	t.Run("Failure - keeps the env details when the PR cannot be fetched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().GetPullRequest(ctx, "owner", "repo", 7).Return(nil, errors.New("not found"))
+
+		prDetails := &models.PRDetails{Owner: "owner", Repo: "repo", PRNumber: 7}
+		fillPRDetails(ctx, mockRepo, prDetails)
+		assert.Equal(t, &models.PRDetails{Owner: "owner", Repo: "repo", PRNumber: 7}, prDetails)
+	})
+}
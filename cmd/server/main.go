@@ -10,7 +10,9 @@ import (
 
 	"code-reviewer-bot/config"
 	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/fakellm"
 	"code-reviewer-bot/internal/handlers"
+	"code-reviewer-bot/internal/storage"
 
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/firebase/genkit/go/plugins/compat_oai/anthropic"
@@ -40,8 +42,13 @@ func RunServer(ctx context.Context, cfg *config.Config) error {
 		return fmt.Errorf("failed to initialize Genkit: %w", err)
 	}
 
+	store, err := storage.New(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create review store: %w", err)
+	}
+
 	router := gin.Default()
-	handlers.RegisterHandlers(router, g, cfg)
+	handlers.RegisterHandlers(router, g, store, cfg)
 
 	router.GET("/", func(c *gin.Context) {
 		c.String(http.StatusOK, "AI Code Reviewer Bot is running.")
@@ -56,18 +63,32 @@ func RunServer(ctx context.Context, cfg *config.Config) error {
 	return router.Run(":" + port)
 }
 
-// initGenkit initializes the Genkit instance and loads the appropriate LLM plugin.
+// initGenkit initializes the Genkit instance, loads the LLM plugin for every
+// provider the configuration refers to and registers the prompt files.
 func initGenkit(ctx context.Context, cfg *config.Config) (*genkit.Genkit, error) {
-	var plugin genkit.Plugin
-	switch cfg.LLM.Provider {
+	var plugins []genkit.Plugin
+	for _, m := range cfg.LLMProviders() {
+		plugin, err := newLLMPlugin(cfg, m.Provider, m.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, plugin)
+	}
+	return genkit.Init(ctx, genkit.WithPlugins(plugins...), genkit.WithPromptDir(cfg.PromptDir))
+}
+
+// newLLMPlugin returns the Genkit plugin for the given provider.
+func newLLMPlugin(cfg *config.Config, provider, apiKey string) (genkit.Plugin, error) {
+	switch provider {
 	case constants.GOOGLEAI:
-		plugin = &googlegenai.GoogleAI{APIKey: cfg.LLM.APIKey}
+		return &googlegenai.GoogleAI{APIKey: apiKey}, nil
 	case constants.OPENAI:
-		plugin = &openai.OpenAI{APIKey: cfg.LLM.APIKey}
+		return &openai.OpenAI{APIKey: apiKey}, nil
 	case constants.CLAUDAI:
-		plugin = &anthropic.Anthropic{Opts: []option.RequestOption{option.WithAPIKey(cfg.LLM.APIKey)}}
+		return &anthropic.Anthropic{Opts: []option.RequestOption{option.WithAPIKey(apiKey)}}, nil
+	case constants.FAKE:
+		return fakellm.New(cfg), nil
 	default:
-		return nil, fmt.Errorf("unsupported LLM provider in config: %s", cfg.LLM.Provider)
+		return nil, fmt.Errorf("unsupported LLM provider in config: %s", provider)
 	}
-	return genkit.Init(ctx, genkit.WithPlugins(plugin))
 }
@@ -3,7 +3,15 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/prompts"
+	"code-reviewer-bot/internal/testcheck"
+	"code-reviewer-bot/internal/utils"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,13 +27,237 @@ type DatabaseConfig struct {
 
 // Config holds the application's configuration.
 type Config struct {
-	VCS              VCSConfig      `yaml:"vcs"`
-	LLM              LLMConfig      `yaml:"llm"`
-	Database         DatabaseConfig `yaml:"database"`
-	ReviewPromptFile string         `yaml:"review_prompt_file"`
-	// This now holds the fully assembled prompt after loading.
-	ReviewPrompt             string `yaml:"review_prompt"`
-	ArchitectureReviewPrompt string `yaml:"architecture_review_prompt"`
+	VCS      VCSConfig      `yaml:"vcs"`
+	LLM      LLMConfig      `yaml:"llm"`
+	Database DatabaseConfig `yaml:"database"`
+	// PromptDir holds the dotprompt files (review.prompt, architecture.prompt, ...).
+	PromptDir string       `yaml:"prompt_dir"`
+	Review    ReviewConfig `yaml:"review"`
+	// LanguagePrompts are checked in order; files matching none use ReviewPrompt.
+	LanguagePrompts []LanguagePromptConfig `yaml:"language_prompts"`
+	Conversation    ConversationConfig     `yaml:"conversation"`
+	Commands        CommandsConfig         `yaml:"commands"`
+}
+
+// CommandsConfig controls slash commands such as "/review" in PR comments, which
+// only users with write access to the repository can run.
+type CommandsConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// ConversationConfig controls replies to PR comments that mention the bot.
+type ConversationConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// BotName is the login of the bot account. Comments mentioning "@BotName" are
+	// answered; comments written by it are never answered.
+	BotName string `yaml:"bot_name"`
+	// MaxHistory caps the earlier comments of a thread passed to the model.
+	MaxHistory int `yaml:"max_history"`
+	// ContextLines is the number of file lines shown on each side of a commented line.
+	ContextLines int `yaml:"context_lines"`
+}
+
+// LanguagePromptConfig holds the review prompt for files matching one of its patterns.
+type LanguagePromptConfig struct {
+	Name string `yaml:"name"`
+	// Match lists file extensions (".go") or globs ("**/migrations/*.sql").
+	Match []string `yaml:"match"`
+	// Prompt names the dotprompt in prompt_dir, e.g. "review.sql" for the variant
+	// file review.sql.prompt. Defaults to "review".
+	Prompt string `yaml:"prompt"`
+	// Rules are language-specific instructions passed to the prompt.
+	Rules string `yaml:"rules"`
+}
+
+// ReviewConfig holds settings that shape a single review run.
+type ReviewConfig struct {
+	// ShowUsage appends token usage and cost to the summary comment.
+	ShowUsage bool         `yaml:"show_usage"`
+	Budget    BudgetConfig `yaml:"budget"`
+	// MinSeverityToPost drops findings below this severity (info, minor, major, critical).
+	MinSeverityToPost string         `yaml:"min_severity_to_post"`
+	Verifier          VerifierConfig `yaml:"verifier"`
+	// Paths limits which changed files are reviewed.
+	Paths PathFilterConfig `yaml:"paths"`
+	// Guidelines are extra instructions passed to the review prompt.
+	Guidelines string `yaml:"guidelines"`
+	// DisabledChecks turns off whole checks: "architecture", "tests" and/or "coverage".
+	DisabledChecks []string `yaml:"disabled_checks"`
+	// Language is the natural language review comments are written in, e.g. "German".
+	Language string `yaml:"language"`
+	// StyleGuides are project documents added to the system part of the review prompt.
+	StyleGuides StyleGuidesConfig `yaml:"style_guides"`
+	// SymbolContext adds the declarations used by a Go hunk to its prompt.
+	SymbolContext SymbolContextConfig `yaml:"symbol_context"`
+	// CodeSearch adds similar code from elsewhere in the repository to each hunk's prompt.
+	CodeSearch CodeSearchConfig `yaml:"code_search"`
+	// Summary posts an overview and file-by-file walkthrough of the PR.
+	Summary SummaryConfig `yaml:"summary"`
+	// Feedback learns from developers rejecting review comments. It needs the database.
+	Feedback FeedbackConfig `yaml:"feedback"`
+	// Tests configures how the missing-test check recognizes test files.
+	Tests TestsConfig `yaml:"tests"`
+	// Coverage reports the changed lines that CI coverage reports leave uncovered.
+	Coverage CoverageConfig `yaml:"coverage"`
+}
+
+// CoverageConfig lists the coverage reports CI produced for the PR head: Go cover
+// profiles, LCOV, Cobertura XML or JaCoCo XML, detected from their content. Relative
+// paths are read from the clone of the PR head. The check reports the added lines
+// the reports instrument but no test ran, with each file's patch coverage; below
+// MinPatchCoverage percent of covered added lines the review fails. 0 never fails.
+type CoverageConfig struct {
+	Reports          []string `yaml:"reports"`
+	MinPatchCoverage float64  `yaml:"min_patch_coverage"`
+}
+
+// TestsConfig overrides the test file conventions of the missing-test check. Patterns
+// maps a language ("go", "python", "javascript", "typescript", "java", "rust",
+// "csharp", "ruby") to the globs of its test files, e.g. python: ["tests/**/*.py"];
+// languages left out keep their built-in conventions.
+type TestsConfig struct {
+	Patterns map[string][]string `yaml:"patterns"`
+}
+
+// FeedbackConfig controls learning from developer feedback. A comment that gets a 👎
+// or a reply such as "not an issue" is rejected; once MinRejections similar comments
+// (same category, word overlap of at least Similarity) were rejected in a repository,
+// matching findings are no longer posted there. 0 selects the defaults, 2 and 0.6.
+type FeedbackConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	MinRejections int     `yaml:"min_rejections"`
+	Similarity    float64 `yaml:"similarity"`
+}
+
+// SummaryConfig controls the PR summary comment: what the PR does, a table of the
+// changed files, risk areas and where reviewers should focus. MaxDiffTokens caps the
+// (estimated) size of the hunks sent to the model; files beyond it are summarized
+// from their names and line counts only. 0 disables the limit.
+type SummaryConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	MaxDiffTokens int  `yaml:"max_diff_tokens"`
+}
+
+// CodeSearchConfig controls retrieval of related code from a keyword index of the
+// clone. The index of each repository is kept in CacheDir, by default the user cache
+// directory, and refreshed for each head commit. TopK caps the snippets per hunk and
+// MaxTokens their (estimated) total size.
+type CodeSearchConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	TopK      int    `yaml:"top_k"`
+	MaxTokens int    `yaml:"max_tokens"`
+	CacheDir  string `yaml:"cache_dir"`
+}
+
+// SymbolContextConfig controls cross-file context for Go changes: identifiers used
+// in the added lines are resolved to their declarations elsewhere in the repository.
+// MaxTokens caps the (estimated) size of the declarations added to each hunk.
+type SymbolContextConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	MaxTokens int  `yaml:"max_tokens"`
+}
+
+// StyleGuidesConfig lists the documents, read from the PR's base branch, that describe
+// the project's conventions. Paths are file names or globs ("docs/adr/*.md") and are
+// added in order until MaxTokens is used up; 0 disables the limit.
+type StyleGuidesConfig struct {
+	Paths     []string `yaml:"paths"`
+	MaxTokens int      `yaml:"max_tokens"`
+}
+
+// PathFilterConfig holds include and exclude globs for changed files. An empty
+// include list means every file is included; excludes always win.
+type PathFilterConfig struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// Allows reports whether a file passes the filters.
+func (f PathFilterConfig) Allows(filePath string) bool {
+	for _, pattern := range f.Exclude {
+		if utils.MatchPath(pattern, filePath) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if utils.MatchPath(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEnabled reports whether the named check is not listed in DisabledChecks.
+func (c ReviewConfig) CheckEnabled(check string) bool {
+	return !slices.Contains(c.DisabledChecks, check)
+}
+
+// validate checks the settings shared by the global config and .ai-review.yaml.
+func (c ReviewConfig) validate() error {
+	if sev := c.MinSeverityToPost; sev != "" && !slices.Contains(constants.SEVERITIES, sev) {
+		return fmt.Errorf("min_severity_to_post must be one of %s, got '%s'", strings.Join(constants.SEVERITIES, ", "), sev)
+	}
+	for _, check := range c.DisabledChecks {
+		if !slices.Contains(constants.CHECKS, check) {
+			return fmt.Errorf("disabled_checks: unknown check '%s' (want %s)", check, strings.Join(constants.CHECKS, ", "))
+		}
+	}
+	if c.StyleGuides.MaxTokens < 0 {
+		return fmt.Errorf("style_guides.max_tokens must not be negative")
+	}
+	if c.SymbolContext.MaxTokens < 0 {
+		return fmt.Errorf("symbol_context.max_tokens must not be negative")
+	}
+	if c.CodeSearch.TopK < 0 || c.CodeSearch.MaxTokens < 0 {
+		return fmt.Errorf("code_search.top_k and code_search.max_tokens must not be negative")
+	}
+	if c.Summary.MaxDiffTokens < 0 {
+		return fmt.Errorf("summary.max_diff_tokens must not be negative")
+	}
+	if c.Feedback.MinRejections < 0 {
+		return fmt.Errorf("feedback.min_rejections must not be negative")
+	}
+	if c.Feedback.Similarity < 0 || c.Feedback.Similarity > 1 {
+		return fmt.Errorf("feedback.similarity must be between 0 and 1")
+	}
+	for _, pattern := range slices.Concat(c.Paths.Include, c.Paths.Exclude, c.StyleGuides.Paths) {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("paths: patterns must not be empty")
+		}
+	}
+	if c.Coverage.MinPatchCoverage < 0 || c.Coverage.MinPatchCoverage > 100 {
+		return fmt.Errorf("coverage.min_patch_coverage must be between 0 and 100")
+	}
+	for language, patterns := range c.Tests.Patterns {
+		if !slices.Contains(testcheck.Languages(), language) {
+			return fmt.Errorf("tests.patterns: unknown language '%s' (want %s)", language, strings.Join(testcheck.Languages(), ", "))
+		}
+		if len(patterns) == 0 || slices.ContainsFunc(patterns, func(p string) bool { return strings.TrimSpace(p) == "" }) {
+			return fmt.Errorf("tests.patterns.%s: patterns must not be empty", language)
+		}
+	}
+	return nil
+}
+
+// VerifierConfig controls the second pass that asks a model to confirm each finding.
+// Provider and ModelName optionally select a different (e.g. cheaper) model; the
+// main fallback chain is used after it, or alone when they are empty.
+type VerifierConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	Provider      string  `yaml:"provider"`
+	ModelName     string  `yaml:"model_name"`
+	APIKey        string  `yaml:"api_key"`
+	MinConfidence float64 `yaml:"min_confidence"`
+}
+
+// BudgetConfig caps the resources a single review may consume. Zero means unlimited.
+type BudgetConfig struct {
+	MaxTokens   int           `yaml:"max_tokens"`
+	MaxLLMCalls int           `yaml:"max_llm_calls"`
+	MaxDuration time.Duration `yaml:"max_duration"`
 }
 
 // VCSConfig holds configuration for the version control system.
@@ -51,10 +283,99 @@ type LLMConfig struct {
 	Provider  string `yaml:"provider"`
 	ModelName string `yaml:"model_name"`
 	APIKey    string `yaml:"api_key"`
+	// Fallbacks are tried in order when the primary model fails.
+	Fallbacks []LLMModelConfig `yaml:"fallbacks"`
+	// Pricing maps a model name to its token prices, used for cost accounting.
+	Pricing map[string]ModelPrice `yaml:"pricing"`
+	// Fake configures the fixture-backed model used by tests and offline demos.
+	Fake FakeLLMConfig `yaml:"fake"`
+}
+
+// FakeLLMConfig holds settings for the "fake" provider. In replay mode it answers from
+// recorded fixtures; in record mode it proxies to a real model and saves the fixtures.
+type FakeLLMConfig struct {
+	Mode           string `yaml:"mode"`
+	FixturesDir    string `yaml:"fixtures_dir"`
+	RecordProvider string `yaml:"record_provider"`
+	RecordModel    string `yaml:"record_model"`
+	RecordAPIKey   string `yaml:"record_api_key"`
+}
+
+// ModelPrice holds the price in USD per million input and output tokens.
+type ModelPrice struct {
+	InputPerMillion  float64 `yaml:"input_per_million"`
+	OutputPerMillion float64 `yaml:"output_per_million"`
+}
+
+// LLMModelConfig identifies a single provider/model pair in the fallback chain.
+type LLMModelConfig struct {
+	Provider  string `yaml:"provider"`
+	ModelName string `yaml:"model_name"`
+	APIKey    string `yaml:"api_key"`
+}
+
+// Chain returns the primary model followed by the configured fallbacks.
+func (c LLMConfig) Chain() []LLMModelConfig {
+	chain := []LLMModelConfig{{Provider: c.Provider, ModelName: c.ModelName, APIKey: c.APIKey}}
+	return append(chain, c.Fallbacks...)
 }
 
-// LoadConfig reads the configuration, loads the base prompt from a file,
-// and assembles the final review prompt.
+// VerifierChain returns the models used to verify findings: the dedicated verifier
+// model, if configured, followed by the main fallback chain.
+func (c *Config) VerifierChain() []LLMModelConfig {
+	chain := c.LLM.Chain()
+	if v := c.Review.Verifier; v.ModelName != "" {
+		chain = append([]LLMModelConfig{{Provider: v.Provider, ModelName: v.ModelName, APIKey: v.APIKey}}, chain...)
+	}
+	return chain
+}
+
+// LLMProviders returns one entry per provider that needs a plugin, across the
+// fallback chain, the verifier model and the real model the fake provider records
+// from. Genkit loads one plugin per provider, so all models of a provider share its
+// API key: the first one set.
+func (c *Config) LLMProviders() []LLMModelConfig {
+	var providers []LLMModelConfig
+	index := make(map[string]int)
+	for _, m := range c.llmModels() {
+		i, ok := index[m.Provider]
+		if !ok {
+			index[m.Provider] = len(providers)
+			providers = append(providers, m)
+		} else if providers[i].APIKey == "" {
+			providers[i].APIKey = m.APIKey
+		}
+	}
+	return providers
+}
+
+// llmModels returns every model entry, in the order LLMProviders considers them.
+func (c *Config) llmModels() []LLMModelConfig {
+	models := c.VerifierChain()
+	if f := c.LLM.Fake; f.Mode == "record" {
+		models = append(models, LLMModelConfig{Provider: f.RecordProvider, ModelName: f.RecordModel, APIKey: f.RecordAPIKey})
+	}
+	return models
+}
+
+// checkProviderKeys rejects models of one provider with different API keys, since
+// only one of the keys could be used.
+func (c *Config) checkProviderKeys() error {
+	keys := make(map[string]string)
+	for _, m := range c.llmModels() {
+		if m.APIKey == "" {
+			continue
+		}
+		if key, ok := keys[m.Provider]; ok && key != m.APIKey {
+			return fmt.Errorf("all models of provider %q must use the same api_key, one plugin is loaded per provider", m.Provider)
+		}
+		keys[m.Provider] = m.APIKey
+	}
+	return nil
+}
+
+// LoadConfig reads the configuration and checks that every prompt it refers to
+// exists in the prompt directory.
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -68,26 +389,76 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
-	// Check if a prompt file is specified.
-	if cfg.ReviewPromptFile == "" {
-		return nil, fmt.Errorf("'review_prompt_file' must be specified in config.yaml")
+	for i, fb := range cfg.LLM.Fallbacks {
+		if fb.Provider == "" || fb.ModelName == "" {
+			return nil, fmt.Errorf("llm.fallbacks[%d] must set both 'provider' and 'model_name'", i)
+		}
 	}
 
-	// Read the base prompt from the specified file.
-	basePromptBytes, err := os.ReadFile(cfg.ReviewPromptFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read review prompt file '%s': %w", cfg.ReviewPromptFile, err)
+	if err := cfg.Review.validate(); err != nil {
+		return nil, fmt.Errorf("review.%w", err)
 	}
-	basePrompt := string(basePromptBytes)
 
-	// Combine the base prompt with the suffix from the yaml file.
-	var finalPrompt strings.Builder
-	finalPrompt.WriteString(basePrompt)
-	finalPrompt.WriteString("\n\n") // Add a separator
-	finalPrompt.WriteString(cfg.ReviewPrompt)
+	if v := cfg.Review.Verifier; v.Enabled {
+		if v.ModelName != "" && v.Provider == "" {
+			return nil, fmt.Errorf("review.verifier.provider must be set together with model_name")
+		}
+	}
 
-	// Overwrite the ReviewPrompt field with the fully assembled prompt.
-	cfg.ReviewPrompt = finalPrompt.String()
+	if err := cfg.checkProviderKeys(); err != nil {
+		return nil, fmt.Errorf("llm: %w", err)
+	}
+
+	if cfg.PromptDir == "" {
+		return nil, fmt.Errorf("'prompt_dir' must be specified in config.yaml")
+	}
+	required := []string{constants.PROMPT_REVIEW, constants.PROMPT_ARCHITECTURE}
+	if cfg.Review.Verifier.Enabled {
+		required = append(required, constants.PROMPT_VERIFICATION)
+	}
+	if len(cfg.Review.StyleGuides.Paths) > 0 {
+		required = append(required, constants.PROMPT_STYLE_GUIDES)
+	}
+	if cfg.Review.Summary.Enabled || cfg.Commands.Enabled {
+		required = append(required, constants.PROMPT_SUMMARY)
+	}
+	if c := cfg.Conversation; c.Enabled {
+		if c.BotName == "" {
+			return nil, fmt.Errorf("conversation.bot_name must be set when conversation is enabled")
+		}
+		if c.MaxHistory < 0 || c.ContextLines < 0 {
+			return nil, fmt.Errorf("conversation.max_history and conversation.context_lines must not be negative")
+		}
+		required = append(required, constants.PROMPT_REPLY)
+	}
+	for i := range cfg.LanguagePrompts {
+		lp := &cfg.LanguagePrompts[i]
+		if len(lp.Match) == 0 {
+			return nil, fmt.Errorf("language_prompts[%d] must set 'match'", i)
+		}
+		if lp.Prompt == "" {
+			lp.Prompt = constants.PROMPT_REVIEW
+		}
+		required = append(required, lp.Prompt)
+	}
+	for _, name := range required {
+		if _, err := prompts.ReadVersion(filepath.Join(cfg.PromptDir, name+".prompt")); err != nil {
+			return nil, fmt.Errorf("invalid prompt %q: %w", name, err)
+		}
+	}
 
 	return &cfg, nil
 }
+
+// PromptFor returns the prompt settings for a file: the first language prompt with a
+// matching pattern, or the default review prompt without extra rules.
+func (c *Config) PromptFor(filePath string) LanguagePromptConfig {
+	for _, lp := range c.LanguagePrompts {
+		for _, pattern := range lp.Match {
+			if utils.MatchPath(pattern, filePath) {
+				return lp
+			}
+		}
+	}
+	return LanguagePromptConfig{Prompt: constants.PROMPT_REVIEW}
+}
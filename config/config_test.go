@@ -0,0 +1,295 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfig writes the yaml and a prompt directory holding the given prompt files
+// to a temp dir; ${DIR} in the yaml is replaced by that dir.
+func writeConfig(t *testing.T, yaml string, promptFiles map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "prompts"), 0o755))
+	for name, content := range promptFiles {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "prompts", name), []byte(content), 0o644))
+	}
+	path := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(os.Expand(yaml, func(string) string { return dir })), 0o644))
+	return path
+}
+
+func versioned(version string) string {
+	return "---\nversion: \"" + version + "\"\n---\nReview {{{codeSnippet}}}\n"
+}
+
+func TestLoadConfig_Prompts(t *testing.T) {
+	basePrompts := map[string]string{"review.prompt": versioned("1"), "architecture.prompt": versioned("1")}
+
+	t.Run("Success - files use the first matching language prompt", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+language_prompts:
+  - name: go
+    match: [".go"]
+    rules: "- Check errors."
+  - name: sql
+    match: ["**/migrations/*.sql"]
+    prompt: review.sql
+`, map[string]string{"review.prompt": versioned("1"), "architecture.prompt": versioned("1"), "review.sql.prompt": versioned("3")})
+		cfg, err := LoadConfig(path)
+		assert.NoError(t, err)
+
+		goPrompt := cfg.PromptFor("internal/service/review_service.go")
+		assert.Equal(t, "review", goPrompt.Prompt)
+		assert.Equal(t, "- Check errors.", goPrompt.Rules)
+		assert.Equal(t, "review.sql", cfg.PromptFor("db/migrations/001_init.sql").Prompt)
+		assert.Equal(t, LanguagePromptConfig{Prompt: "review"}, cfg.PromptFor("schema.sql"))
+	})
+
+	t.Run("Failure - prompt_dir is not set", func(t *testing.T) {
+		_, err := LoadConfig(writeConfig(t, `llm: {provider: googleai}`, nil))
+		assert.ErrorContains(t, err, "'prompt_dir' must be specified")
+	})
+
+	t.Run("Failure - prompt without a version", func(t *testing.T) {
+		path := writeConfig(t, `prompt_dir: "${DIR}/prompts"`, map[string]string{
+			"review.prompt":       "---\ndescription: no version\n---\nReview\n",
+			"architecture.prompt": versioned("1"),
+		})
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, "does not declare a version")
+	})
+
+	t.Run("Failure - verifier enabled without a verification prompt", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+review:
+  verifier:
+    enabled: true
+`, basePrompts)
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, `invalid prompt "verification"`)
+	})
+
+	t.Run("Failure - style guides without a style guides prompt", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+review:
+  style_guides:
+    paths: ["CONTRIBUTING.md"]
+`, basePrompts)
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, `invalid prompt "style_guides"`)
+	})
+
+	t.Run("Failure - conversation enabled without a bot name", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+conversation:
+  enabled: true
+`, basePrompts)
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, "conversation.bot_name must be set")
+	})
+
+	t.Run("Failure - summary enabled without a summary prompt", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+review:
+  summary:
+    enabled: true
+`, basePrompts)
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, `invalid prompt "summary"`)
+	})
+
+	t.Run("Failure - feedback similarity above 1", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+review:
+  feedback:
+    enabled: true
+    similarity: 60
+`, basePrompts)
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, "feedback.similarity must be between 0 and 1")
+	})
+
+	t.Run("Failure - language prompt without patterns", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+language_prompts:
+  - name: go
+`, basePrompts)
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, "language_prompts[0] must set 'match'")
+	})
+
+	t.Run("Failure - missing language prompt file", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+language_prompts:
+  - match: [".ts"]
+    prompt: review.typescript
+`, basePrompts)
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, "review.typescript.prompt")
+	})
+}
+
+func TestLoadConfig_LLMProviders(t *testing.T) {
+	basePrompts := map[string]string{"review.prompt": versioned("1"), "architecture.prompt": versioned("1")}
+
+	t.Run("Failure - one provider with two API keys", func(t *testing.T) {
+		path := writeConfig(t, `
+prompt_dir: "${DIR}/prompts"
+llm:
+  provider: openai
+  model_name: "openai/gpt-4o"
+  api_key: "key-1"
+  fallbacks:
+    - provider: openai
+      model_name: "openai/gpt-4o-mini"
+      api_key: "key-2"
+`, basePrompts)
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, `all models of provider "openai" must use the same api_key`)
+	})
+}
+
+func TestLLMProviders(t *testing.T) {
+	t.Run("Success - one entry per provider with the key that is set", func(t *testing.T) {
+		cfg := &Config{LLM: LLMConfig{
+			Provider: "openai", ModelName: "openai/gpt-4o",
+			Fallbacks: []LLMModelConfig{
+				{Provider: "googleai", ModelName: "googleai/gemini-2.0-flash", APIKey: "google-key"},
+				{Provider: "openai", ModelName: "openai/gpt-4o-mini", APIKey: "openai-key"},
+			},
+		}}
+		assert.Equal(t, []LLMModelConfig{
+			{Provider: "openai", ModelName: "openai/gpt-4o", APIKey: "openai-key"},
+			{Provider: "googleai", ModelName: "googleai/gemini-2.0-flash", APIKey: "google-key"},
+		}, cfg.LLMProviders())
+	})
+}
+
+func TestLoadConfig_ShippedConfig(t *testing.T) {
+	t.Setenv("API_KEY", "test")
+	data, err := os.ReadFile("config.yaml")
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	abs, err := filepath.Abs("prompts")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(string(data), "/app/config/prompts", abs)), 0o644))
+
+	cfg, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "review", cfg.PromptFor("main.go").Prompt)
+}
+
+func TestParseRepoConfig(t *testing.T) {
+	t.Run("Success - parses every setting", func(t *testing.T) {
+		rc, err := ParseRepoConfig([]byte(`
+paths:
+  include: ["src/**"]
+  exclude: ["**/*.pb.go"]
+guidelines: Wrap errors with %w.
+disabled_checks: [architecture]
+min_severity_to_post: major
+language: German
+show_usage: false
+`))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"src/**"}, rc.Paths.Include)
+		assert.NotNil(t, rc.ShowUsage)
+		assert.False(t, *rc.ShowUsage)
+		assert.Equal(t, []string{"architecture"}, rc.DisabledChecks)
+		assert.Equal(t, "German", rc.Language)
+	})
+
+	t.Run("Success - empty file", func(t *testing.T) {
+		rc, err := ParseRepoConfig(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &RepoConfig{}, rc)
+	})
+
+	t.Run("Failure - unknown key", func(t *testing.T) {
+		_, err := ParseRepoConfig([]byte("disable: [tests]\n"))
+		assert.ErrorContains(t, err, "field disable not found")
+	})
+
+	t.Run("Failure - unknown check", func(t *testing.T) {
+		_, err := ParseRepoConfig([]byte("disabled_checks: [lint]\n"))
+		assert.ErrorContains(t, err, "unknown check 'lint'")
+	})
+
+	t.Run("Failure - unknown severity", func(t *testing.T) {
+		_, err := ParseRepoConfig([]byte("min_severity_to_post: blocker\n"))
+		assert.ErrorContains(t, err, "min_severity_to_post must be one of")
+	})
+
+	t.Run("Failure - test patterns of an unknown language", func(t *testing.T) {
+		_, err := ParseRepoConfig([]byte("tests:\n  patterns:\n    kotlin: [\"*Test.kt\"]\n"))
+		assert.ErrorContains(t, err, "unknown language 'kotlin'")
+	})
+
+	t.Run("Failure - coverage report outside the repository", func(t *testing.T) {
+		_, err := ParseRepoConfig([]byte("coverage:\n  reports: [\"../secrets/coverage.out\"]\n"))
+		assert.ErrorContains(t, err, "must be a relative path inside the repository")
+		_, err = ParseRepoConfig([]byte("coverage:\n  reports: [\"/etc/coverage.out\"]\n"))
+		assert.ErrorContains(t, err, "must be a relative path inside the repository")
+	})
+
+	t.Run("Failure - coverage threshold above 100", func(t *testing.T) {
+		_, err := ParseRepoConfig([]byte("coverage:\n  min_patch_coverage: 120\n"))
+		assert.ErrorContains(t, err, "coverage.min_patch_coverage must be between 0 and 100")
+	})
+}
+
+func TestWithRepoConfig(t *testing.T) {
+	global := &Config{Review: ReviewConfig{
+		ShowUsage:         true,
+		MinSeverityToPost: "minor",
+		Guidelines:        "Use structured logging.",
+		DisabledChecks:    []string{"architecture"},
+		Paths:             PathFilterConfig{Exclude: []string{"vendor/**"}},
+		StyleGuides:       StyleGuidesConfig{Paths: []string{"CONTRIBUTING.md"}, MaxTokens: 2000},
+		Tests:             TestsConfig{Patterns: map[string][]string{"go": {"*_test.go"}, "python": {"test_*.py"}}},
+		Coverage:          CoverageConfig{Reports: []string{"/ci/coverage.out"}, MinPatchCoverage: 60},
+	}}
+	merged := global.WithRepoConfig(&RepoConfig{
+		Paths:             PathFilterConfig{Include: []string{"src/**"}, Exclude: []string{"src/gen/**"}},
+		Guidelines:        "Wrap errors.",
+		DisabledChecks:    []string{"tests", "architecture"},
+		MinSeverityToPost: "major",
+		Language:          "German",
+		StyleGuides:       StyleGuidesConfig{Paths: []string{"docs/STYLE.md"}},
+		Tests:             TestsConfig{Patterns: map[string][]string{"python": {"tests/**/*.py"}}},
+		Coverage:          CoverageConfig{Reports: []string{"build/lcov.info"}, MinPatchCoverage: 80},
+		ShowUsage:         new(bool),
+	})
+
+	assert.Equal(t, "major", merged.Review.MinSeverityToPost)
+	assert.False(t, merged.Review.ShowUsage)
+	assert.Equal(t, StyleGuidesConfig{Paths: []string{"docs/STYLE.md"}, MaxTokens: 2000}, merged.Review.StyleGuides)
+	assert.Equal(t, "German", merged.Review.Language)
+	assert.Equal(t, "Use structured logging.\nWrap errors.", merged.Review.Guidelines)
+	assert.Equal(t, []string{"architecture", "tests"}, merged.Review.DisabledChecks)
+	assert.Equal(t, map[string][]string{"go": {"*_test.go"}, "python": {"tests/**/*.py"}}, merged.Review.Tests.Patterns)
+	assert.Equal(t, CoverageConfig{Reports: []string{"/ci/coverage.out", "build/lcov.info"}, MinPatchCoverage: 80}, merged.Review.Coverage)
+	assert.True(t, merged.Review.Paths.Allows("src/main.go"))
+	assert.False(t, merged.Review.Paths.Allows("src/gen/api.go"))
+	assert.False(t, merged.Review.Paths.Allows("vendor/x/y.go"))
+	assert.False(t, merged.Review.Paths.Allows("docs/readme.md"))
+
+	assert.Equal(t, []string{"architecture"}, global.Review.DisabledChecks, "global config must not change")
+	assert.True(t, global.Review.ShowUsage)
+	assert.Equal(t, []string{"vendor/**"}, global.Review.Paths.Exclude)
+	assert.Equal(t, []string{"test_*.py"}, global.Review.Tests.Patterns["python"])
+}
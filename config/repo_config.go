@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfig holds the per-repository overrides read from .ai-review.yaml on the
+// base branch. Its keys mirror the "review" section of the global config.
+type RepoConfig struct {
+	Paths             PathFilterConfig  `yaml:"paths"`
+	Guidelines        string            `yaml:"guidelines"`
+	DisabledChecks    []string          `yaml:"disabled_checks"`
+	MinSeverityToPost string            `yaml:"min_severity_to_post"`
+	Language          string            `yaml:"language"`
+	StyleGuides       StyleGuidesConfig `yaml:"style_guides"`
+	Tests             TestsConfig       `yaml:"tests"`
+	Coverage          CoverageConfig    `yaml:"coverage"`
+	// ShowUsage is nil when the repository leaves the global setting alone.
+	ShowUsage *bool `yaml:"show_usage"`
+}
+
+// ParseRepoConfig decodes and validates a .ai-review.yaml file. Unknown keys are
+// rejected so that typos do not silently fall back to the defaults.
+func ParseRepoConfig(data []byte) (*RepoConfig, error) {
+	var rc RepoConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	review := ReviewConfig{
+		Paths:             rc.Paths,
+		DisabledChecks:    rc.DisabledChecks,
+		MinSeverityToPost: rc.MinSeverityToPost,
+		StyleGuides:       rc.StyleGuides,
+		Tests:             rc.Tests,
+		Coverage:          rc.Coverage,
+	}
+	if err := review.validate(); err != nil {
+		return nil, err
+	}
+	// Reports named by a repository must stay inside its clone; links are followed
+	// and checked again when the reports are read.
+	for _, report := range rc.Coverage.Reports {
+		if !filepath.IsLocal(report) {
+			return nil, fmt.Errorf("coverage.reports: '%s' must be a relative path inside the repository", report)
+		}
+	}
+	return &rc, nil
+}
+
+// WithRepoConfig returns a copy of the config with the repository overrides merged
+// over the review settings. Excludes, guidelines and disabled checks add to the
+// global values, and so do coverage reports; includes, the severity threshold, the
+// language, the style guide settings, the test patterns of each language listed,
+// the coverage threshold and show_usage replace them.
+func (c *Config) WithRepoConfig(rc *RepoConfig) *Config {
+	merged := *c
+	review := &merged.Review
+	if len(rc.Paths.Include) > 0 {
+		review.Paths.Include = rc.Paths.Include
+	}
+	review.Paths.Exclude = slices.Concat(c.Review.Paths.Exclude, rc.Paths.Exclude)
+	if rc.Guidelines != "" {
+		review.Guidelines = strings.TrimSpace(strings.Join([]string{c.Review.Guidelines, rc.Guidelines}, "\n"))
+	}
+	for _, check := range rc.DisabledChecks {
+		if !slices.Contains(review.DisabledChecks, check) {
+			review.DisabledChecks = append(slices.Clip(review.DisabledChecks), check)
+		}
+	}
+	if rc.MinSeverityToPost != "" {
+		review.MinSeverityToPost = rc.MinSeverityToPost
+	}
+	if rc.Language != "" {
+		review.Language = rc.Language
+	}
+	if len(rc.StyleGuides.Paths) > 0 {
+		review.StyleGuides.Paths = rc.StyleGuides.Paths
+	}
+	if rc.StyleGuides.MaxTokens > 0 {
+		review.StyleGuides.MaxTokens = rc.StyleGuides.MaxTokens
+	}
+	review.Coverage.Reports = slices.Concat(c.Review.Coverage.Reports, rc.Coverage.Reports)
+	if rc.Coverage.MinPatchCoverage > 0 {
+		review.Coverage.MinPatchCoverage = rc.Coverage.MinPatchCoverage
+	}
+	if len(rc.Tests.Patterns) > 0 {
+		review.Tests.Patterns = maps.Clone(c.Review.Tests.Patterns)
+		if review.Tests.Patterns == nil {
+			review.Tests.Patterns = make(map[string][]string)
+		}
+		maps.Copy(review.Tests.Patterns, rc.Tests.Patterns)
+	}
+	if rc.ShowUsage != nil {
+		review.ShowUsage = *rc.ShowUsage
+	}
+	return &merged
+}
+
+// String renders a short description of the overrides for logging.
+func (rc *RepoConfig) String() string {
+	return fmt.Sprintf("include=%v exclude=%v disabled_checks=%v min_severity=%q language=%q style_guides=%v tests=%v coverage=%v",
+		rc.Paths.Include, rc.Paths.Exclude, rc.DisabledChecks, rc.MinSeverityToPost, rc.Language, rc.StyleGuides.Paths, rc.Tests.Patterns, rc.Coverage.Reports)
+}
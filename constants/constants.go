@@ -4,6 +4,8 @@ const (
 	OPENED                string = "opened"
 	SYNCHRONIZE           string = "synchronize"
 	REOPENED              string = "reopened"
+	CREATED               string = "created"
+	CLOSED                string = "closed"
 	GITHUB                string = "Github"
 	GITHUB_ENDPOINT       string = "/github/webhook"
 	GITHUB_TOKEN          string = "GITHUB_TOKEN"
@@ -17,13 +19,56 @@ const (
 	REPO_NAME             string = "REPO_NAME"
 	GITHUB_REPOSITORY     string = "GITHUB_REPOSITORY"
 	GITEA_REPOSITORY      string = "GITEA_REPOSITORY"
+	BASE_REF              string = "BASE_REF"
+	GITHUB_BASE_REF       string = "GITHUB_BASE_REF"
 
 	OPEN          string = "open"
 	REVIEW_PROMPT string = "review_prompt"
 	GOOGLEAI      string = "googleai"
 	OPENAI        string = "openai"
 	CLAUDAI       string = "claudai"
+	FAKE          string = "fake"
+
+	REVIEW_SUCCESS string = "success"
+	REVIEW_FAILED  string = "failed"
+
+	FEEDBACK_ACCEPTED string = "accepted"
+	FEEDBACK_REJECTED string = "rejected"
+
+	SEVERITY_INFO     string = "info"
+	SEVERITY_MINOR    string = "minor"
+	SEVERITY_MAJOR    string = "major"
+	SEVERITY_CRITICAL string = "critical"
+
+	CATEGORY_BUG         string = "bug"
+	CATEGORY_SECURITY    string = "security"
+	CATEGORY_PERFORMANCE string = "performance"
+	CATEGORY_STYLE       string = "style"
+	CATEGORY_NAMING      string = "naming"
+
+	PROMPT_REVIEW       string = "review"
+	PROMPT_ARCHITECTURE string = "architecture"
+	PROMPT_VERIFICATION string = "verification"
+	PROMPT_STYLE_GUIDES string = "style_guides"
+	PROMPT_SUMMARY      string = "summary"
+	PROMPT_REPLY        string = "reply"
+
+	CHECK_ARCHITECTURE string = "architecture"
+	CHECK_TESTS        string = "tests"
+	CHECK_COVERAGE     string = "coverage"
+
+	REPO_CONFIG_FILE string = ".ai-review.yaml"
+
+	// SUMMARY_COMMENT_MARKER identifies the bot's summary comment, which is edited
+	// in place on every run instead of posting a new one.
+	SUMMARY_COMMENT_MARKER string = "<!-- ai-review:summary -->"
 
 	GITHUB_URL string = "github.com"
 	GITEA_URL  string = "gitea.com"
 )
+
+// CHECKS lists the checks that can be turned off with review.disabled_checks.
+var CHECKS = []string{CHECK_ARCHITECTURE, CHECK_TESTS, CHECK_COVERAGE}
+
+// SEVERITIES lists the finding severities from least to most severe.
+var SEVERITIES = []string{SEVERITY_INFO, SEVERITY_MINOR, SEVERITY_MAJOR, SEVERITY_CRITICAL}
@@ -0,0 +1,337 @@
+// Package codesearch keeps a BM25 keyword index of a cloned repository on disk, so
+// that code similar to a diff hunk can be retrieved without an embedding service.
+//
+// Files are split into overlapping windows of lines, and each window is indexed as a
+// document. The index is stored outside the clone, so it outlives the clone of a
+// single review. It is refreshed for each head commit, and files whose git blob did
+// not change are not re-read.
+package codesearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// formatVersion invalidates indexes written by an incompatible version.
+	formatVersion = 1
+	// windowLines and windowStep set the size and overlap of the indexed windows.
+	windowLines = 30
+	windowStep  = 15
+	// maxFileBytes skips generated or minified files that are too large to be useful.
+	maxFileBytes = 256 << 10
+
+	// BM25 parameters.
+	k1 = 1.2
+	b  = 0.75
+)
+
+// sourceExtensions are the files worth retrieving as related code.
+var sourceExtensions = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true,
+	".java": true, ".kt": true, ".rs": true, ".cs": true, ".rb": true, ".php": true,
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".hpp": true, ".swift": true,
+	".scala": true, ".sql": true, ".sh": true, ".proto": true,
+}
+
+// skipDirs are never indexed, wherever they appear in the tree.
+var skipDirs = []string{"vendor", "node_modules", "third_party"}
+
+// Index is a BM25 index of a repository at one commit.
+type Index struct {
+	Version int
+	// Head is the commit the index reflects.
+	Head string
+	// Files maps a path to the git blob it was indexed from and its windows.
+	Files map[string]*File
+
+	repoPath string
+	df       map[string]int
+	docs     int
+	avgLen   float64
+}
+
+// File holds the indexed windows of one file.
+type File struct {
+	Blob    string
+	Windows []Window
+}
+
+// Window is a range of lines indexed as one document.
+type Window struct {
+	StartLine int
+	EndLine   int
+	Terms     map[string]int
+	Len       int
+}
+
+// Result is a window of code matching a query.
+type Result struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Score     float64
+	Code      string
+}
+
+// Open loads the index stored at storePath for the repository checked out at
+// repoPath and brings it up to date with HEAD. Files unchanged since the stored
+// commit keep their windows; only added or modified files are read and tokenized
+// again. The refreshed index is saved back to storePath.
+func Open(ctx context.Context, repoPath, storePath string) (*Index, error) {
+	head, err := git(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	head = strings.TrimSpace(head)
+
+	idx := load(storePath)
+	idx.repoPath = repoPath
+	if idx.Head != head {
+		if err := idx.refresh(ctx, head); err != nil {
+			return nil, err
+		}
+		if err := idx.save(storePath); err != nil {
+			return nil, err
+		}
+	}
+	idx.computeStats()
+	return idx, nil
+}
+
+// load reads a stored index, or returns an empty one when there is none or it was
+// written by another format version.
+func load(storePath string) *Index {
+	empty := &Index{Version: formatVersion, Files: make(map[string]*File)}
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		return empty
+	}
+	var idx Index
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&idx); err != nil || idx.Version != formatVersion {
+		return empty
+	}
+	if idx.Files == nil {
+		idx.Files = make(map[string]*File)
+	}
+	return &idx
+}
+
+func (idx *Index) save(storePath string) error {
+	if err := os.MkdirAll(filepath.Dir(storePath), 0o755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(idx); err != nil {
+		return err
+	}
+	// Reviews of the same repository may save concurrently; each writes its own
+	// temporary file and the last rename wins.
+	tmp, err := os.CreateTemp(filepath.Dir(storePath), filepath.Base(storePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), storePath)
+}
+
+// refresh re-indexes the files whose blob differs from the indexed one and drops the
+// files that no longer exist at head.
+func (idx *Index) refresh(ctx context.Context, head string) error {
+	out, err := git(ctx, idx.repoPath, "ls-tree", "-r", "-z", head)
+	if err != nil {
+		return err
+	}
+	blobs := make(map[string]string)
+	for _, entry := range strings.Split(out, "\x00") {
+		// "<mode> blob <sha>\t<path>"
+		meta, filePath, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" || !indexable(filePath) {
+			continue
+		}
+		blobs[filePath] = fields[2]
+	}
+
+	for filePath := range idx.Files {
+		if _, ok := blobs[filePath]; !ok {
+			delete(idx.Files, filePath)
+		}
+	}
+	for filePath, blob := range blobs {
+		if f, ok := idx.Files[filePath]; ok && f.Blob == blob {
+			continue
+		}
+		// The working tree is checked out at head, so it holds the blob's content.
+		data, err := os.ReadFile(filepath.Join(idx.repoPath, filepath.FromSlash(filePath)))
+		if err != nil || len(data) > maxFileBytes || bytes.IndexByte(data, 0) != -1 {
+			delete(idx.Files, filePath)
+			continue
+		}
+		idx.Files[filePath] = &File{Blob: blob, Windows: windows(string(data))}
+	}
+	idx.Head = head
+	return nil
+}
+
+func indexable(filePath string) bool {
+	if !sourceExtensions[path.Ext(filePath)] {
+		return false
+	}
+	for _, dir := range strings.Split(path.Dir(filePath), "/") {
+		for _, skip := range skipDirs {
+			if dir == skip {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// windows splits content into overlapping windows of lines and counts their terms.
+func windows(content string) []Window {
+	lines := strings.Split(content, "\n")
+	var result []Window
+	for start := 0; start < len(lines); start += windowStep {
+		end := min(start+windowLines, len(lines))
+		terms := make(map[string]int)
+		n := 0
+		for _, term := range Tokenize(strings.Join(lines[start:end], "\n")) {
+			terms[term]++
+			n++
+		}
+		if n > 0 {
+			result = append(result, Window{StartLine: start + 1, EndLine: end, Terms: terms, Len: n})
+		}
+		if end == len(lines) {
+			break
+		}
+	}
+	return result
+}
+
+func (idx *Index) computeStats() {
+	idx.df = make(map[string]int)
+	idx.docs = 0
+	total := 0
+	for _, f := range idx.Files {
+		for _, w := range f.Windows {
+			idx.docs++
+			total += w.Len
+			for term := range w.Terms {
+				idx.df[term]++
+			}
+		}
+	}
+	if idx.docs > 0 {
+		idx.avgLen = float64(total) / float64(idx.docs)
+	}
+}
+
+// Search returns up to k windows ranked by BM25 score against the query. Windows for
+// which skip returns true are ignored, and at most one of any overlapping windows
+// of the same file is returned.
+func (idx *Index) Search(query string, k int, skip func(path string, startLine, endLine int) bool) []Result {
+	terms := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		terms[term] = true
+	}
+	if len(terms) == 0 || idx.docs == 0 {
+		return nil
+	}
+
+	var candidates []Result
+	for filePath, f := range idx.Files {
+		for _, w := range f.Windows {
+			score := 0.0
+			for term := range terms {
+				tf := float64(w.Terms[term])
+				if tf == 0 {
+					continue
+				}
+				df := float64(idx.df[term])
+				idf := math.Log(1 + (float64(idx.docs)-df+0.5)/(df+0.5))
+				score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(w.Len)/idx.avgLen))
+			}
+			if score > 0 && (skip == nil || !skip(filePath, w.StartLine, w.EndLine)) {
+				candidates = append(candidates, Result{Path: filePath, StartLine: w.StartLine, EndLine: w.EndLine, Score: score})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if candidates[i].Path != candidates[j].Path {
+			return candidates[i].Path < candidates[j].Path
+		}
+		return candidates[i].StartLine < candidates[j].StartLine
+	})
+
+	var results []Result
+	for _, c := range candidates {
+		if len(results) == k {
+			break
+		}
+		if overlapsAny(results, c) {
+			continue
+		}
+		code, err := idx.readLines(c.Path, c.StartLine, c.EndLine)
+		if err != nil {
+			continue
+		}
+		c.Code = code
+		results = append(results, c)
+	}
+	return results
+}
+
+func overlapsAny(results []Result, c Result) bool {
+	for _, r := range results {
+		if r.Path == c.Path && c.StartLine <= r.EndLine && r.StartLine <= c.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+// readLines returns the lines of a file in the working tree, which is checked out at
+// the indexed head.
+func (idx *Index) readLines(filePath string, start, end int) (string, error) {
+	data, err := os.ReadFile(filepath.Join(idx.repoPath, filepath.FromSlash(filePath)))
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(string(data), "\n")
+	if start > len(lines) {
+		return "", fmt.Errorf("%s has only %d lines", filePath, len(lines))
+	}
+	return strings.Join(lines[start-1:min(end, len(lines))], "\n"), nil
+}
+
+func git(ctx context.Context, repoPath string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repoPath}, args...)...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
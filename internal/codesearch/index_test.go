@@ -0,0 +1,123 @@
+package codesearch
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Run("Success - splits identifiers and drops stop words", func(t *testing.T) {
+		terms := Tokenize("func cleanCommentBody(s string) error { return parseHTTPRequest_v2(s) }")
+		assert.Equal(t, []string{"cleancommentbody", "clean", "comment", "body", "parsehttprequestv2", "parse", "http", "request", "v2"}, terms)
+	})
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		path := filepath.Join(repo, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	commit := func() {
+		run("add", "-A")
+		run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "change")
+	}
+	run("init", "--quiet")
+	write("utils/comments.go", "package utils\n\n// cleanCommentBody trims markdown from a comment body.\nfunc cleanCommentBody(body string) string {\n\treturn strings.TrimSpace(body)\n}\n")
+	write("handlers/github.go", "package handlers\n\nfunc verifySignature(payload []byte, secret string) bool {\n\treturn hmac.Equal(payload, []byte(secret))\n}\n")
+	write("vendor/lib/lib.go", "package lib\n\nfunc cleanCommentBody() {}\n")
+	write("README.md", "cleanCommentBody is documented here")
+	commit()
+
+	store := filepath.Join(t.TempDir(), "owner", "repo.gob")
+
+	t.Run("Success - builds the index and ranks related code", func(t *testing.T) {
+		idx, err := Open(ctx, repo, store)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"utils/comments.go", "handlers/github.go"}, keys(idx.Files))
+
+		results := idx.Search("+\tbody = cleanComment(body)", 2, nil)
+		assert.NotEmpty(t, results)
+		assert.Equal(t, "utils/comments.go", results[0].Path)
+		assert.Equal(t, 1, results[0].StartLine)
+		assert.Contains(t, results[0].Code, "func cleanCommentBody(body string) string {")
+	})
+
+	t.Run("Success - skip excludes windows", func(t *testing.T) {
+		idx, err := Open(ctx, repo, store)
+		assert.NoError(t, err)
+		results := idx.Search("cleanCommentBody", 2, func(path string, start, end int) bool { return path == "utils/comments.go" })
+		assert.Empty(t, results)
+	})
+
+	t.Run("Success - refreshes only changed files for a new head", func(t *testing.T) {
+		write("handlers/gitea.go", "package handlers\n\nfunc verifyGiteaSignature(payload []byte) bool { return false }\n")
+		assert.NoError(t, os.Remove(filepath.Join(repo, "handlers/github.go")))
+		commit()
+		// An unchanged file is not read again: its stored windows are reused even
+		// though the working tree now differs.
+		write("utils/comments.go", "package utils\n")
+
+		idx, err := Open(ctx, repo, store)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"utils/comments.go", "handlers/gitea.go"}, keys(idx.Files))
+		assert.NotEmpty(t, idx.Files["utils/comments.go"].Windows[0].Terms["cleancommentbody"])
+		assert.Equal(t, "handlers/gitea.go", idx.Search("verifyGiteaSignature", 1, nil)[0].Path)
+
+		head, err := git(ctx, repo, "rev-parse", "HEAD")
+		assert.NoError(t, err)
+		assert.Equal(t, strings.TrimSpace(head), idx.Head)
+	})
+
+	t.Run("Success - the stored index outlives the clone", func(t *testing.T) {
+		clone := t.TempDir()
+		out, err := exec.Command("git", "clone", "--quiet", repo, clone).CombinedOutput()
+		assert.NoError(t, err, string(out))
+		// The fresh clone is at the indexed head, so nothing is read from it.
+		assert.NoError(t, os.WriteFile(filepath.Join(clone, "handlers/gitea.go"), []byte("package handlers\n"), 0o644))
+
+		idx, err := Open(ctx, clone, store)
+		assert.NoError(t, err)
+		assert.Equal(t, "handlers/gitea.go", idx.Search("verifyGiteaSignature", 1, nil)[0].Path)
+	})
+
+	t.Run("Failure - not a git repository", func(t *testing.T) {
+		_, err := Open(ctx, t.TempDir(), store)
+		assert.Error(t, err)
+	})
+}
+
+func TestWindows(t *testing.T) {
+	t.Run("Success - overlapping windows cover every line", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 50; i++ {
+			lines = append(lines, "value")
+		}
+		ws := windows(strings.Join(lines, "\n"))
+		assert.Len(t, ws, 3)
+		assert.Equal(t, [2]int{1, 30}, [2]int{ws[0].StartLine, ws[0].EndLine})
+		assert.Equal(t, [2]int{16, 45}, [2]int{ws[1].StartLine, ws[1].EndLine})
+		assert.Equal(t, [2]int{31, 50}, [2]int{ws[2].StartLine, ws[2].EndLine})
+	})
+}
+
+func keys(files map[string]*File) []string {
+	var result []string
+	for k := range files {
+		result = append(result, k)
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package codesearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are keywords and names common to most languages; matching on them says
+// nothing about whether two pieces of code are related.
+var stopWords = map[string]bool{
+	"if": true, "else": true, "for": true, "return": true, "func": true, "function": true,
+	"def": true, "var": true, "let": true, "const": true, "type": true, "struct": true,
+	"class": true, "new": true, "nil": true, "null": true, "none": true, "true": true,
+	"false": true, "err": true, "error": true, "string": true, "int": true, "package": true,
+	"import": true, "from": true, "public": true, "private": true, "static": true,
+	"void": true, "self": true, "this": true, "the": true, "and": true, "or": true,
+	"not": true, "in": true, "is": true, "to": true, "of": true, "ctx": true,
+}
+
+// Tokenize splits code into lower-case search terms. Identifiers are kept whole and
+// also split into their camelCase and snake_case words, so that "cleanCommentBody"
+// matches "clean_comment_body" and "commentBody". Stop words and one-letter terms
+// are dropped.
+func Tokenize(code string) []string {
+	var terms []string
+	add := func(term string) {
+		term = strings.ToLower(term)
+		if len(term) > 1 && !stopWords[term] {
+			terms = append(terms, term)
+		}
+	}
+	for _, ident := range strings.FieldsFunc(code, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		words := splitIdentifier(ident)
+		if len(words) > 1 {
+			add(strings.ReplaceAll(ident, "_", ""))
+		}
+		for _, word := range words {
+			add(word)
+		}
+	}
+	return terms
+}
+
+// splitIdentifier splits an identifier at underscores and case changes, keeping
+// acronyms together: "parseHTTPRequest_v2" becomes parse, HTTP, Request, v2.
+func splitIdentifier(ident string) []string {
+	var words []string
+	for _, part := range strings.Split(ident, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+			acronymEnd := i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
@@ -0,0 +1,133 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// parseGoProfile reads a profile written by "go test -coverprofile". Each line is a
+// block, "file.go:startLine.startCol,endLine.endCol statements count", and every
+// line of an executed block counts as covered.
+func parseGoProfile(data []byte) (*Report, error) {
+	r := newReport()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		i := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[i+1:])
+		if i < 0 || len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: malformed block %q", n, line)
+		}
+		start, end, ok := strings.Cut(fields[0], ",")
+		startLine, err1 := strconv.Atoi(strings.Split(start, ".")[0])
+		endLine, err2 := strconv.Atoi(strings.Split(end, ".")[0])
+		count, err3 := strconv.Atoi(fields[2])
+		if !ok || err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("line %d: malformed block %q", n, line)
+		}
+		for l := startLine; l <= endLine; l++ {
+			r.add(line[:i], l, count > 0)
+		}
+	}
+	return r, scanner.Err()
+}
+
+// parseLCOV reads an LCOV tracefile: "SF:" starts a file, "DA:line,hits" records
+// a line and "end_of_record" ends the file.
+func parseLCOV(data []byte) (*Report, error) {
+	r := newReport()
+	var file string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = strings.TrimPrefix(line, "SF:")
+		case line == "end_of_record":
+			file = ""
+		case strings.HasPrefix(line, "DA:"):
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if file == "" || len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: DA outside of a file record", n)
+			}
+			lineNo, err1 := strconv.Atoi(fields[0])
+			hits, err2 := strconv.ParseFloat(fields[1], 64)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("line %d: malformed %q", n, line)
+			}
+			r.add(file, lineNo, hits > 0)
+		}
+	}
+	return r, scanner.Err()
+}
+
+type coberturaReport struct {
+	Classes []struct {
+		Filename string `xml:"filename,attr"`
+		Lines    []struct {
+			Number int    `xml:"number,attr"`
+			Hits   string `xml:"hits,attr"`
+		} `xml:"lines>line"`
+	} `xml:"packages>package>classes>class"`
+}
+
+// parseCobertura reads a Cobertura XML report. Class file names are relative to one
+// of the report's sources, so they are matched by suffix.
+func parseCobertura(data []byte) (*Report, error) {
+	var doc coberturaReport
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	r := newReport()
+	for _, class := range doc.Classes {
+		for _, line := range class.Lines {
+			hits, err := strconv.ParseFloat(line.Hits, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: malformed hits %q", class.Filename, line.Number, line.Hits)
+			}
+			r.add(class.Filename, line.Number, hits > 0)
+		}
+	}
+	return r, nil
+}
+
+type jacocoReport struct {
+	Packages []struct {
+		Name        string `xml:"name,attr"`
+		SourceFiles []struct {
+			Name  string `xml:"name,attr"`
+			Lines []struct {
+				Number int `xml:"nr,attr"`
+				// CoveredInstructions counts the line's instructions that ran.
+				CoveredInstructions int `xml:"ci,attr"`
+			} `xml:"line"`
+		} `xml:"sourcefile"`
+	} `xml:"package"`
+}
+
+// parseJaCoCo reads a JaCoCo XML report. Source files are named by their package
+// path, e.g. "com/example/Cart.java", and a line is covered when any of its
+// instructions ran.
+func parseJaCoCo(data []byte) (*Report, error) {
+	var doc jacocoReport
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	r := newReport()
+	for _, pkg := range doc.Packages {
+		for _, file := range pkg.SourceFiles {
+			for _, line := range file.Lines {
+				r.add(path.Join(pkg.Name, file.Name), line.Number, line.CoveredInstructions > 0)
+			}
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,117 @@
+// Package coverage reads the line coverage of CI coverage reports: Go cover
+// profiles (coverage.out), LCOV, Cobertura XML and JaCoCo XML.
+package coverage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// ErrUnknownFormat is returned for data that is none of the supported formats.
+var ErrUnknownFormat = errors.New("unrecognized coverage report format")
+
+// Report holds the instrumented lines of each file a report covers.
+type Report struct {
+	// files maps a path as written in the report to its instrumented lines, each
+	// true when it was executed.
+	files map[string]map[int]bool
+}
+
+func newReport() *Report {
+	return &Report{files: make(map[string]map[int]bool)}
+}
+
+// add records one instrumented line; a line covered by any block stays covered.
+func (r *Report) add(file string, line int, covered bool) {
+	file = path.Clean(strings.ReplaceAll(file, `\`, "/"))
+	if r.files[file] == nil {
+		r.files[file] = make(map[int]bool)
+	}
+	r.files[file][line] = r.files[file][line] || covered
+}
+
+// Load reads and parses the coverage report at path.
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Parse detects the format of a coverage report and parses it.
+func Parse(data []byte) (*Report, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return parseGoProfile(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseXML(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:")):
+		return parseLCOV(trimmed)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// parseXML tells Cobertura from JaCoCo by the root element.
+func parseXML(data []byte) (*Report, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "coverage":
+				return parseCobertura(data)
+			case "report":
+				return parseJaCoCo(data)
+			}
+			return nil, fmt.Errorf("%w: root element <%s>", ErrUnknownFormat, start.Name.Local)
+		}
+	}
+}
+
+// Merge adds the lines of other; a line covered in either report is covered.
+func (r *Report) Merge(other *Report) {
+	for file, lines := range other.files {
+		for line, covered := range lines {
+			r.add(file, line, covered)
+		}
+	}
+}
+
+// Lines returns the instrumented lines of a repository-relative file, each true
+// when covered. Reports name files by absolute path, import path or package path,
+// so a report file matches when either path ends with the other at a "/". A file
+// that matches more than one report file is skipped rather than given the coverage
+// of another file with the same name.
+func (r *Report) Lines(file string) (map[int]bool, bool) {
+	if lines, ok := r.files[file]; ok {
+		return lines, true
+	}
+	var match string
+	for name := range r.files {
+		if !strings.HasSuffix(name, "/"+file) && !strings.HasSuffix(file, "/"+name) {
+			continue
+		}
+		if match != "" {
+			return nil, false
+		}
+		match = name
+	}
+	if match == "" {
+		return nil, false
+	}
+	return r.files[match], true
+}
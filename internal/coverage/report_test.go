@@ -0,0 +1,136 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+		file   string
+		want   map[int]bool
+	}{
+		{
+			name: "Go cover profile",
+			report: "mode: set\n" +
+				"code-reviewer-bot/internal/cart/cart.go:3.20,5.2 1 1\n" +
+				"code-reviewer-bot/internal/cart/cart.go:5.2,7.3 1 0\n" +
+				"code-reviewer-bot/internal/cart/cart.go:9.1,9.10 1 0\n",
+			file: "internal/cart/cart.go",
+			want: map[int]bool{3: true, 4: true, 5: true, 6: false, 7: false, 9: false},
+		},
+		{
+			name:   "LCOV",
+			report: "TN:\nSF:/ci/workspace/web/app.ts\nDA:1,4\nDA:2,0\nend_of_record\nSF:/ci/workspace/web/util.ts\nDA:1,1\nend_of_record\n",
+			file:   "web/app.ts",
+			want:   map[int]bool{1: true, 2: false},
+		},
+		{
+			name: "Cobertura",
+			report: `<?xml version="1.0" ?>
+<coverage line-rate="0.5">
+  <sources><source>/ci/workspace/src</source></sources>
+  <packages><package name="shop"><classes>
+    <class name="cart.py" filename="shop/cart.py">
+      <lines><line number="1" hits="1"/><line number="4" hits="0"/></lines>
+    </class>
+  </classes></package></packages>
+</coverage>`,
+			file: "src/shop/cart.py",
+			want: map[int]bool{1: true, 4: false},
+		},
+		{
+			name: "JaCoCo",
+			report: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="shop">
+  <package name="com/example">
+    <sourcefile name="Cart.java">
+      <line nr="5" mi="0" ci="3" mb="0" cb="0"/>
+      <line nr="6" mi="2" ci="0" mb="0" cb="0"/>
+    </sourcefile>
+  </package>
+</report>`,
+			file: "src/main/java/com/example/Cart.java",
+			want: map[int]bool{5: true, 6: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("Success - "+tt.name, func(t *testing.T) {
+			r, err := Parse([]byte(tt.report))
+			assert.NoError(t, err)
+			lines, ok := r.Lines(tt.file)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, lines)
+		})
+	}
+
+	t.Run("Failure - unknown format", func(t *testing.T) {
+		_, err := Parse([]byte("{\"coverage\": {}}"))
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+		_, err = Parse([]byte("<html></html>"))
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+	})
+
+	t.Run("Failure - malformed Go block", func(t *testing.T) {
+		_, err := Parse([]byte("mode: set\ncart.go:3.20 1 1\n"))
+		assert.ErrorContains(t, err, "line 2: malformed block")
+	})
+}
+
+func TestReport(t *testing.T) {
+	unit, err := Parse([]byte("SF:web/app.ts\nDA:1,0\nDA:2,0\nend_of_record\n"))
+	assert.NoError(t, err)
+	e2e, err := Parse([]byte("SF:web/app.ts\nDA:2,3\nDA:3,0\nend_of_record\n"))
+	assert.NoError(t, err)
+
+	t.Run("Success - merged reports keep lines covered by either", func(t *testing.T) {
+		unit.Merge(e2e)
+		lines, ok := unit.Lines("web/app.ts")
+		assert.True(t, ok)
+		assert.Equal(t, map[int]bool{1: false, 2: true, 3: false}, lines)
+	})
+
+	t.Run("Success - names that end on a path component", func(t *testing.T) {
+		profile, err := Parse([]byte("mode: set\n" +
+			"example.com/mod/cmd/server/main.go:3.1,4.2 1 0\n" +
+			"example.com/mod/cmd/reviewer/main.go:5.1,5.9 1 1\n" +
+			"example.com/mod/a/util.go:7.1,7.9 1 1\n"))
+		assert.NoError(t, err)
+
+		lines, ok := profile.Lines("cmd/server/main.go")
+		assert.True(t, ok)
+		assert.Equal(t, map[int]bool{3: false, 4: false}, lines)
+		lines, ok = profile.Lines("src/example.com/mod/a/util.go")
+		assert.True(t, ok)
+		assert.Equal(t, map[int]bool{7: true}, lines)
+		_, ok = profile.Lines("b/util.go")
+		assert.False(t, ok, "a/util.go must not cover b/util.go")
+		_, ok = profile.Lines("server/cmd/server/main.go.orig")
+		assert.False(t, ok)
+	})
+
+	t.Run("Failure - names matching several report files are skipped", func(t *testing.T) {
+		profile, err := Parse([]byte("mode: set\n" +
+			"example.com/mod/cmd/server/main.go:3.1,4.2 1 0\n" +
+			"example.com/mod/main.go:5.1,5.9 1 1\n"))
+		assert.NoError(t, err)
+		_, ok := profile.Lines("main.go")
+		assert.False(t, ok)
+
+		lcov, err := Parse([]byte("SF:util.go\nDA:1,1\nend_of_record\nSF:pkg/util.go\nDA:2,1\nend_of_record\n"))
+		assert.NoError(t, err)
+		_, ok = lcov.Lines("src/pkg/util.go")
+		assert.False(t, ok)
+	})
+
+	t.Run("Failure - file not in the report", func(t *testing.T) {
+		_, ok := unit.Lines("web/other.ts")
+		assert.False(t, ok)
+		_, ok = unit.Lines("app.ts/web/app.ts.bak")
+		assert.False(t, ok)
+	})
+}
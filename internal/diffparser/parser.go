@@ -72,3 +72,27 @@ func Parse(diffStr string) []*DiffChunk {
 
 	return chunks
 }
+
+// AddedLineNumbers returns the new-file line numbers of the lines the hunk adds.
+// With countRemovals, a removed line counts for the line that follows it, so that
+// deleting a statement touches the code around it.
+func (c *DiffChunk) AddedLineNumbers(countRemovals bool) []int {
+	var lines []int
+	n := c.StartLineNew
+	for _, line := range strings.Split(c.CodeSnippet, "\n")[1:] {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, n)
+			n++
+		case strings.HasPrefix(line, "-"):
+			if countRemovals {
+				lines = append(lines, n)
+			}
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		default:
+			n++
+		}
+	}
+	return lines
+}
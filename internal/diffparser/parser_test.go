@@ -32,3 +32,19 @@ index abc..def 100644
 	assert.Equal(t, "README.md", chunks[1].FilePath)
 	assert.Equal(t, 1, chunks[1].StartLineNew)
 }
+
+func TestDiffChunk_AddedLineNumbers(t *testing.T) {
+	chunk := &DiffChunk{
+		FilePath:     "main.go",
+		CodeSnippet:  "@@ -10,4 +10,4 @@\n func main() {\n-\tprintln(1)\n+\tprintln(2)\n+\tprintln(3)\n }\n-// end\n\\ No newline at end of file",
+		StartLineNew: 10,
+	}
+
+	t.Run("Success - added lines only", func(t *testing.T) {
+		assert.Equal(t, []int{11, 12}, chunk.AddedLineNumbers(false))
+	})
+
+	t.Run("Success - removals count for the following line", func(t *testing.T) {
+		assert.Equal(t, []int{11, 11, 12, 14}, chunk.AddedLineNumbers(true))
+	})
+}
@@ -0,0 +1,187 @@
+package fakellm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/prompts"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+const (
+	ModeReplay = "replay"
+	ModeRecord = "record"
+)
+
+// Fixture is a recorded model response, stored as <fixtures_dir>/<request key>.json.
+type Fixture struct {
+	Prompt   string              `json:"prompt"`
+	Response string              `json:"response"`
+	Usage    *ai.GenerationUsage `json:"usage,omitempty"`
+}
+
+// FakeLLM is a Genkit plugin that serves deterministic model responses from fixture
+// files keyed by a hash of the rendered prompt. In record mode it forwards requests
+// to a real model and saves the answers as new fixtures.
+type FakeLLM struct {
+	Mode        string
+	FixturesDir string
+	RecordModel string
+	// Models are the model names to register, without the "fake/" prefix.
+	Models []string
+	// PromptDir holds prompt files whose front-matter may name further fake models.
+	PromptDir string
+
+	g *genkit.Genkit
+}
+
+// New builds the plugin from the config, registering every "fake" model of the
+// chain and of the verifier. Fake models named in the front-matter of the prompt
+// files are added when the plugin is initialized.
+func New(cfg *config.Config) *FakeLLM {
+	f := &FakeLLM{
+		Mode:        cfg.LLM.Fake.Mode,
+		FixturesDir: cfg.LLM.Fake.FixturesDir,
+		RecordModel: cfg.LLM.Fake.RecordModel,
+		PromptDir:   cfg.PromptDir,
+	}
+	if f.Mode == "" {
+		f.Mode = ModeReplay
+	}
+	for _, m := range cfg.VerifierChain() {
+		if m.Provider == constants.FAKE {
+			f.addModel(m.ModelName)
+		}
+	}
+	return f
+}
+
+// addModel adds a model to register unless it is already listed.
+func (f *FakeLLM) addModel(name string) {
+	name = strings.TrimPrefix(name, constants.FAKE+"/")
+	if !slices.Contains(f.Models, name) {
+		f.Models = append(f.Models, name)
+	}
+}
+
+func (f *FakeLLM) Name() string {
+	return constants.FAKE
+}
+
+// Init registers the fake models with Genkit.
+func (f *FakeLLM) Init(ctx context.Context, g *genkit.Genkit) error {
+	if f.Mode != ModeReplay && f.Mode != ModeRecord {
+		return fmt.Errorf("fakellm: unknown mode %q (want %q or %q)", f.Mode, ModeReplay, ModeRecord)
+	}
+	if f.FixturesDir == "" {
+		return fmt.Errorf("fakellm: fixtures_dir is not set")
+	}
+	if f.Mode == ModeRecord && f.RecordModel == "" {
+		return fmt.Errorf("fakellm: record mode requires record_model")
+	}
+	if f.PromptDir != "" {
+		names, err := prompts.Models(f.PromptDir)
+		if err != nil {
+			return fmt.Errorf("fakellm: failed to read the models of the prompt files: %w", err)
+		}
+		for _, name := range names {
+			if strings.HasPrefix(name, constants.FAKE+"/") {
+				f.addModel(name)
+			}
+		}
+	}
+	f.g = g
+	for _, name := range f.Models {
+		genkit.DefineModel(g, constants.FAKE, name, &ai.ModelInfo{
+			Label:    "Fake " + name,
+			Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true},
+		}, f.generate)
+	}
+	return nil
+}
+
+func (f *FakeLLM) generate(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+	key := RequestKey(req)
+	path := filepath.Join(f.FixturesDir, key+".json")
+
+	if f.Mode == ModeRecord {
+		return f.record(ctx, req, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fakellm: no fixture for request %s (re-run in record mode to create it): %w", key, err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("fakellm: invalid fixture %s: %w", path, err)
+	}
+	return &ai.ModelResponse{
+		Request:      req,
+		Message:      ai.NewModelTextMessage(fixture.Response),
+		FinishReason: ai.FinishReasonStop,
+		Usage:        fixture.Usage,
+	}, nil
+}
+
+// record forwards the request to the real model and saves its answer as a fixture.
+func (f *FakeLLM) record(ctx context.Context, req *ai.ModelRequest, path string) (*ai.ModelResponse, error) {
+	res, err := genkit.Generate(ctx, f.g,
+		ai.WithModelName(f.RecordModel),
+		ai.WithMessages(req.Messages...),
+		ai.WithConfig(req.Config))
+	if err != nil {
+		return nil, fmt.Errorf("fakellm: recording from %s failed: %w", f.RecordModel, err)
+	}
+
+	fixture := Fixture{Prompt: requestText(req), Response: res.Text(), Usage: res.Usage}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(f.FixturesDir, 0o755); err != nil {
+		return nil, fmt.Errorf("fakellm: failed to create fixtures dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("fakellm: failed to save fixture: %w", err)
+	}
+	res.Request = req
+	return res, nil
+}
+
+// boundaryPattern matches the random tag that delimits untrusted content in the
+// bot's prompts.
+var boundaryPattern = regexp.MustCompile(`untrusted-[0-9a-f]{16}`)
+
+// RequestKey returns the fixture key for a request: the first 16 hex characters of
+// the SHA-256 of its rendered messages. The random boundary tags are replaced by a
+// fixed one first, so the same prompt always maps to the same fixture.
+func RequestKey(req *ai.ModelRequest) string {
+	text := boundaryPattern.ReplaceAllString(requestText(req), "untrusted-boundary")
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// requestText renders the request's messages as "role: text" blocks.
+func requestText(req *ai.ModelRequest) string {
+	var b strings.Builder
+	for _, msg := range req.Messages {
+		b.WriteString(string(msg.Role))
+		b.WriteString(": ")
+		b.WriteString(msg.Text())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package fakellm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-reviewer-bot/config"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGenkit(t *testing.T, mode, dir, recordModel string) *genkit.Genkit {
+	cfg := &config.Config{LLM: config.LLMConfig{
+		Provider:  "fake",
+		ModelName: "fake/reviewer",
+		Fake:      config.FakeLLMConfig{Mode: mode, FixturesDir: dir, RecordModel: recordModel},
+	}}
+	g, err := genkit.Init(context.Background(), genkit.WithPlugins(New(cfg)))
+	assert.NoError(t, err)
+	return g
+}
+
+func TestFakeLLM_Replay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	g := newTestGenkit(t, ModeReplay, dir, "")
+
+	t.Run("Success - answers from the matching fixture", func(t *testing.T) {
+		req := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("review this")}}
+		fixture, _ := json.Marshal(Fixture{Response: `[]`, Usage: &ai.GenerationUsage{InputTokens: 3, OutputTokens: 1}})
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, RequestKey(req)+".json"), fixture, 0o644))
+
+		res, err := genkit.Generate(ctx, g, ai.WithModelName("fake/reviewer"), ai.WithPrompt("review this"))
+		assert.NoError(t, err)
+		assert.Equal(t, "[]", res.Text())
+		assert.Equal(t, 3, res.Usage.InputTokens)
+	})
+
+	t.Run("Success - the boundary tag does not change the key", func(t *testing.T) {
+		first := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("<untrusted-0123456789abcdef>x</untrusted-0123456789abcdef>")}}
+		second := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("<untrusted-fedcba9876543210>x</untrusted-fedcba9876543210>")}}
+		assert.Equal(t, RequestKey(first), RequestKey(second))
+	})
+
+	t.Run("Failure - no fixture for the prompt", func(t *testing.T) {
+		_, err := genkit.Generate(ctx, g, ai.WithModelName("fake/reviewer"), ai.WithPrompt("unknown prompt"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no fixture for request")
+	})
+}
+
+func TestFakeLLM_Record(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	g := newTestGenkit(t, ModeRecord, dir, "test/echo")
+	genkit.DefineModel(g, "test", "echo", nil, func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		return &ai.ModelResponse{Message: ai.NewModelTextMessage("echo: " + req.Messages[0].Text())}, nil
+	})
+
+	res, err := genkit.Generate(ctx, g, ai.WithModelName("fake/reviewer"), ai.WithPrompt("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: hello", res.Text())
+
+	req := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hello")}}
+	data, err := os.ReadFile(filepath.Join(dir, RequestKey(req)+".json"))
+	assert.NoError(t, err)
+	var fixture Fixture
+	assert.NoError(t, json.Unmarshal(data, &fixture))
+	assert.Equal(t, "echo: hello", fixture.Response)
+	assert.Equal(t, "user: hello\n", fixture.Prompt)
+}
+
+func TestNew(t *testing.T) {
+	promptDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(promptDir, "summary.prompt"), []byte("---\nversion: \"1\"\nmodel: fake/summarizer\n---\nHi\n"), 0o644))
+	cfg := &config.Config{
+		LLM: config.LLMConfig{
+			Provider:  "fake",
+			ModelName: "fake/reviewer",
+			Fake:      config.FakeLLMConfig{FixturesDir: t.TempDir()},
+		},
+		Review:    config.ReviewConfig{Verifier: config.VerifierConfig{Enabled: true, Provider: "fake", ModelName: "fake/verifier"}},
+		PromptDir: promptDir,
+	}
+
+	g, err := genkit.Init(context.Background(), genkit.WithPlugins(New(cfg)))
+	assert.NoError(t, err)
+	for _, name := range []string{"fake/reviewer", "fake/verifier", "fake/summarizer"} {
+		assert.NotNil(t, genkit.LookupModel(g, "fake", strings.TrimPrefix(name, "fake/")), name)
+	}
+}
+
+func TestFakeLLM_InitValidatesConfig(t *testing.T) {
+	_, err := genkit.Init(context.Background(), genkit.WithPlugins(&FakeLLM{Mode: "bogus", FixturesDir: "x"}))
+	assert.Error(t, err)
+}
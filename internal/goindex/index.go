@@ -0,0 +1,261 @@
+// Package goindex indexes the top-level declarations of a Go repository so that the
+// identifiers used in a diff hunk can be resolved to their definitions.
+package goindex
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// Symbol is a top-level declaration: a function, method, type, constant or variable.
+type Symbol struct {
+	// Name is the declared name; methods are named "Type.Method".
+	Name string
+	// Package is the name of the declaring package.
+	Package string
+	// File is the path of the declaring file, relative to the indexed root.
+	File string
+	Line int
+	// Decl is the declaration source with its doc comment. Function bodies are left
+	// out; struct fields and their comments are kept.
+	Decl string
+}
+
+// Index maps identifiers to the symbols declaring them.
+type Index struct {
+	symbols map[string][]Symbol
+}
+
+// Ref is an identifier used in code, with the package qualifier it was selected
+// from ("fmt" in fmt.Println), if any.
+type Ref struct {
+	Qualifier string
+	Name      string
+}
+
+// gofmtPrinter prints declarations the way gofmt lays them out.
+var gofmtPrinter = printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+
+// skipDirs are directories whose Go files are never indexed.
+var skipDirs = map[string]bool{"vendor": true, "testdata": true, "node_modules": true}
+
+// Build parses every Go file under root and indexes its top-level declarations.
+// Files that do not parse are skipped.
+func Build(root string) (*Index, error) {
+	idx := &Index{symbols: make(map[string][]Symbol)}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			log.Printf("goindex: skipping %s: %v", path, err)
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		idx.addFile(fset, filepath.ToSlash(rel), file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (idx *Index) addFile(fset *token.FileSet, path string, file *ast.File) {
+	add := func(key, name string, node ast.Node, doc *ast.CommentGroup, decl string) {
+		idx.symbols[key] = append(idx.symbols[key], Symbol{
+			Name:    name,
+			Package: file.Name.Name,
+			File:    path,
+			Line:    fset.Position(node.Pos()).Line,
+			Decl:    withDoc(doc, decl),
+		})
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			sig := *d
+			sig.Body, sig.Doc = nil, nil
+			add(d.Name.Name, FuncName(d), d, d.Doc, render(fset, &sig, nil))
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				doc := specDoc(d, spec)
+				// The doc is added by withDoc; the printer would put it after the keyword.
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					ts := *s
+					ts.Doc = nil
+					add(s.Name.Name, s.Name.Name, s, doc, "type "+render(fset, &ts, file.Comments))
+				case *ast.ValueSpec:
+					vs := *s
+					vs.Doc = nil
+					text := d.Tok.String() + " " + render(fset, &vs, file.Comments)
+					for _, n := range s.Names {
+						if n.Name != "_" {
+							add(n.Name, n.Name, s, doc, text)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// Lookup returns the symbols an identifier may refer to. When the reference is
+// qualified by the name of an indexed package, only that package's symbols match;
+// otherwise (a method call on a value, or an unindexed package) every symbol with
+// the name does.
+func (idx *Index) Lookup(ref Ref) []Symbol {
+	symbols := idx.symbols[ref.Name]
+	if ref.Qualifier == "" {
+		return symbols
+	}
+	var inPackage []Symbol
+	for _, s := range symbols {
+		if s.Package == ref.Qualifier {
+			inPackage = append(inPackage, s)
+		}
+	}
+	if len(inPackage) > 0 {
+		return inPackage
+	}
+	return symbols
+}
+
+// Identifiers returns the distinct identifiers used in the given Go source lines, in
+// order of first use. Lines are scanned one at a time, so they do not need to form
+// valid Go together.
+func Identifiers(code string) []Ref {
+	var refs []Ref
+	seen := make(map[Ref]bool)
+	for _, line := range strings.Split(code, "\n") {
+		var s scanner.Scanner
+		src := []byte(line)
+		s.Init(token.NewFileSet().AddFile("", -1, len(src)), src, func(token.Position, string) {}, 0)
+
+		var prev, prevPrev token.Token
+		var prevLit string
+		for {
+			_, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+			if tok == token.IDENT {
+				ref := Ref{Name: lit}
+				if prev == token.PERIOD && prevPrev == token.IDENT {
+					ref.Qualifier = prevLit
+				}
+				if !seen[ref] {
+					seen[ref] = true
+					refs = append(refs, ref)
+				}
+				prevLit = lit
+			}
+			prevPrev, prev = prev, tok
+		}
+	}
+	return refs
+}
+
+// FuncName returns the qualified name of a function: "Type.Method" for methods and
+// the plain name for functions.
+func FuncName(fn *ast.FuncDecl) string {
+	if recv := receiverType(fn); recv != "" {
+		return recv + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// receiverType returns the receiver type name of a method, without pointer or type
+// parameters, or "" for a plain function.
+func receiverType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// specDoc returns the doc comment of a spec, falling back to the declaration's doc
+// when the declaration is not a parenthesized group.
+func specDoc(decl *ast.GenDecl, spec ast.Spec) *ast.CommentGroup {
+	var doc *ast.CommentGroup
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		doc = s.Doc
+	case *ast.ValueSpec:
+		doc = s.Doc
+	}
+	if doc == nil && !decl.Lparen.IsValid() {
+		doc = decl.Doc
+	}
+	return doc
+}
+
+// render prints a node together with the given comments that lie inside it, such
+// as struct field comments.
+func render(fset *token.FileSet, node ast.Node, comments []*ast.CommentGroup) string {
+	var inside []*ast.CommentGroup
+	for _, c := range comments {
+		if c.Pos() >= node.Pos() && c.End() <= node.End() {
+			inside = append(inside, c)
+		}
+	}
+	var buf bytes.Buffer
+	var target any = node
+	if inside != nil {
+		target = &printer.CommentedNode{Node: node, Comments: inside}
+	}
+	if err := gofmtPrinter.Fprint(&buf, fset, target); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func withDoc(doc *ast.CommentGroup, decl string) string {
+	if doc == nil {
+		return decl
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(doc.Text(), "\n"), "\n") {
+		b.WriteString(strings.TrimRight("// "+line, " "))
+		b.WriteString("\n")
+	}
+	b.WriteString(decl)
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package goindex
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return root
+}
+
+func TestBuild(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"store/store.go": `package store
+
+// Store persists reviews.
+type Store struct {
+	// DSN is the connection string.
+	DSN  string
+	pool int
+}
+
+// Save writes a review. It retries once.
+func (s *Store) Save(id int, body string) error {
+	return nil
+}
+
+const (
+	// MaxRetries caps retries.
+	MaxRetries = 3
+	minDelay   = 1
+)
+
+// Open connects to the database.
+func Open[T any](dsn string) (*Store, error) { return &Store{DSN: dsn}, nil }
+`,
+		"other/save.go":     "package other\n\nfunc Save() {}\n",
+		"vendor/x/x.go":     "package x\n\nfunc Vendored() {}\n",
+		"testdata/bad.go":   "package bad\n\nfunc Fixture() {}\n",
+		"broken/broken.go":  "package broken\n\nfunc {",
+		".hidden/hidden.go": "package hidden\n\nfunc Hidden() {}\n",
+		"README.md":         "# not go",
+	})
+
+	idx, err := Build(root)
+	assert.NoError(t, err)
+
+	t.Run("Success - struct with fields and doc", func(t *testing.T) {
+		symbols := idx.Lookup(Ref{Name: "Store"})
+		assert.Len(t, symbols, 1)
+		assert.Equal(t, "store/store.go", symbols[0].File)
+		assert.Equal(t, 4, symbols[0].Line)
+		assert.Equal(t, "// Store persists reviews.\ntype Store struct {\n\t// DSN is the connection string.\n\tDSN  string\n\tpool int\n}", symbols[0].Decl)
+	})
+
+	t.Run("Success - method signature without body", func(t *testing.T) {
+		symbols := idx.Lookup(Ref{Qualifier: "s", Name: "Save"})
+		assert.Len(t, symbols, 2)
+		i := slices.IndexFunc(symbols, func(s Symbol) bool { return s.Name == "Store.Save" })
+		assert.NotEqual(t, -1, i)
+		assert.Equal(t, "// Save writes a review. It retries once.\nfunc (s *Store) Save(id int, body string) error", symbols[i].Decl)
+	})
+
+	t.Run("Success - qualifier selects the package", func(t *testing.T) {
+		symbols := idx.Lookup(Ref{Qualifier: "other", Name: "Save"})
+		assert.Len(t, symbols, 1)
+		assert.Equal(t, "func Save()", symbols[0].Decl)
+	})
+
+	t.Run("Success - grouped constants and generic functions", func(t *testing.T) {
+		assert.Equal(t, "// MaxRetries caps retries.\nconst MaxRetries = 3", idx.Lookup(Ref{Name: "MaxRetries"})[0].Decl)
+		assert.Equal(t, "// Open connects to the database.\nfunc Open[T any](dsn string) (*Store, error)", idx.Lookup(Ref{Name: "Open"})[0].Decl)
+	})
+
+	t.Run("Success - skips vendored, testdata, hidden and broken files", func(t *testing.T) {
+		for _, name := range []string{"Vendored", "Fixture", "Hidden"} {
+			assert.Empty(t, idx.Lookup(Ref{Name: name}), name)
+		}
+	})
+}
+
+func TestIdentifiers(t *testing.T) {
+	t.Run("Success - identifiers with qualifiers in order", func(t *testing.T) {
+		refs := Identifiers("\terr := store.Open(dsn) // Ignored comment\n\tfmt.Println(\"Store\", err)\n")
+		assert.Equal(t, []Ref{
+			{Name: "err"}, {Name: "store"}, {Qualifier: "store", Name: "Open"}, {Name: "dsn"},
+			{Name: "fmt"}, {Qualifier: "fmt", Name: "Println"},
+		}, refs)
+	})
+
+	t.Run("Success - tolerates incomplete code", func(t *testing.T) {
+		assert.Equal(t, []Ref{{Name: "x"}, {Name: "y"}}, Identifiers("x := `unterminated\ny)"))
+	})
+}
+
+func TestFuncName(t *testing.T) {
+	src := "package x\nfunc Open() {}\nfunc (s *Store) Save() {}\nfunc (l List[T]) Len() int { return 0 }\n"
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, decl := range file.Decls {
+		names = append(names, FuncName(decl.(*ast.FuncDecl)))
+	}
+	assert.Equal(t, []string{"Open", "Store.Save", "List.Len"}, names)
+}
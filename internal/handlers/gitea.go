@@ -15,6 +15,7 @@ import (
 	"code-reviewer-bot/internal/models"
 	"code-reviewer-bot/internal/repository"
 	"code-reviewer-bot/internal/service"
+	"code-reviewer-bot/internal/storage"
 
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/gin-gonic/gin"
@@ -32,13 +33,41 @@ type GiteaPullRequestHook struct {
 	} `json:"repository"`
 	PullRequest struct {
 		Title   string `json:"title"`
+		Body    string `json:"body"`
 		HTMLURL string `json:"html_url"`
 		Head    struct {
 			Ref string `json:"ref"`
 		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
 	} `json:"pull_request"`
 }
 
+// GiteaIssueCommentHook represents Gitea's issue_comment and pull_request_comment
+// webhook payloads.
+type GiteaIssueCommentHook struct {
+	Action string `json:"action"`
+	// IsPull is set when the comment was written on a pull request.
+	IsPull bool `json:"is_pull"`
+	Issue  struct {
+		Number int64 `json:"number"`
+	} `json:"issue"`
+	Comment struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"comment"`
+	Repo struct {
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Name string `json:"name"`
+	} `json:"repository"`
+}
+
 // GiteaWebhookHandler handles incoming Gitea webhooks.
 type GiteaWebhookHandler struct {
 	reviewService *service.ReviewService
@@ -46,9 +75,9 @@ type GiteaWebhookHandler struct {
 }
 
 // NewGiteaWebhookHandler creates a new handler.
-func NewGiteaWebhookHandler(g *genkit.Genkit, cfg *config.Config, secret string) (*GiteaWebhookHandler, error) {
+func NewGiteaWebhookHandler(g *genkit.Genkit, store storage.ReviewStore, cfg *config.Config, secret string) (*GiteaWebhookHandler, error) {
 	repo := repository.NewGiteaRepository(context.Background(), cfg.VCS.Gitea.BaseURL, cfg.VCS.Gitea.Token)
-	reviewService := service.NewReviewService(repo, g, cfg)
+	reviewService := service.NewReviewService(repo, store, g, cfg)
 	return &GiteaWebhookHandler{
 		reviewService: reviewService,
 		secret:        secret,
@@ -70,6 +99,15 @@ func (h *GiteaWebhookHandler) Handle(c *gin.Context) {
 		return
 	}
 
+	switch c.GetHeader("X-Gitea-Event") {
+	case "issue_comment", "pull_request_comment":
+		h.handleComment(c)
+	default:
+		h.handlePullRequest(c)
+	}
+}
+
+func (h *GiteaWebhookHandler) handlePullRequest(c *gin.Context) {
 	var payload GiteaPullRequestHook
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		c.String(http.StatusBadRequest, "Bad Request")
@@ -81,20 +119,76 @@ func (h *GiteaWebhookHandler) Handle(c *gin.Context) {
 		log.Printf("Received Gitea PR event: %s for PR #%d", action, payload.Number)
 		go h.processPullRequest(&payload)
 		c.String(http.StatusOK, "Event received.")
+	} else if action == constants.CLOSED {
+		// Reactions to the last round of comments arrive after its review ran.
+		go h.collectFeedback(&payload)
+		c.String(http.StatusOK, "Event received.")
 	} else {
 		log.Printf("Ignoring Gitea PR action: %s", action)
 		c.String(http.StatusOK, "Event ignored.")
 	}
 }
 
+func (h *GiteaWebhookHandler) handleComment(c *gin.Context) {
+	var payload GiteaIssueCommentHook
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.String(http.StatusBadRequest, "Bad Request")
+		return
+	}
+	if payload.Action != constants.CREATED || !payload.IsPull {
+		c.String(http.StatusOK, "Event ignored.")
+		return
+	}
+	comment := &models.Mention{
+		Owner:     payload.Repo.Owner.Login,
+		Repo:      payload.Repo.Name,
+		PRNumber:  int(payload.Issue.Number),
+		CommentID: payload.Comment.ID,
+		Author:    payload.Comment.User.Login,
+		Body:      payload.Comment.Body,
+	}
+	if commands := h.reviewService.ParseCommands(c.Request.Context(), comment.Author, comment.Body); len(commands) > 0 {
+		log.Printf("Received Gitea commands in PR #%d", payload.Issue.Number)
+		go h.runCommands(comment, commands)
+	} else if h.reviewService.MentionsBot(c.Request.Context(), comment.Author, comment.Body) {
+		log.Printf("Received Gitea mention in PR #%d", payload.Issue.Number)
+		go h.replyToMention(comment)
+	} else {
+		c.String(http.StatusOK, "Event ignored.")
+		return
+	}
+	c.String(http.StatusOK, "Event received.")
+}
+
+func (h *GiteaWebhookHandler) runCommands(comment *models.Mention, commands []service.Command) {
+	if err := h.reviewService.RunCommands(constants.GITEA_URL, context.Background(), comment, commands); err != nil {
+		log.Printf("Commands failed for Gitea PR #%d: %v", comment.PRNumber, err)
+	}
+}
+
+func (h *GiteaWebhookHandler) replyToMention(mention *models.Mention) {
+	if err := h.reviewService.ReplyToMention(context.Background(), mention); err != nil {
+		log.Printf("Reply failed for Gitea PR #%d: %v", mention.PRNumber, err)
+	}
+}
+
+func (h *GiteaWebhookHandler) collectFeedback(payload *GiteaPullRequestHook) {
+	prDetails := &models.PRDetails{Owner: payload.Repo.Owner.Login, Repo: payload.Repo.Name, PRNumber: int(payload.Number)}
+	if err := h.reviewService.CollectFeedback(context.Background(), prDetails); err != nil {
+		log.Printf("Collecting feedback failed for Gitea PR #%d: %v", prDetails.PRNumber, err)
+	}
+}
+
 func (h *GiteaWebhookHandler) processPullRequest(payload *GiteaPullRequestHook) {
 	prDetails := &models.PRDetails{
-		Owner:    payload.Repo.Owner.Login,
-		Repo:     payload.Repo.Name,
-		PRNumber: int(payload.Number),
-		Title:    payload.PullRequest.Title,
-		Branch:   payload.PullRequest.Head.Ref,
-		URL:      payload.PullRequest.HTMLURL,
+		Owner:      payload.Repo.Owner.Login,
+		Repo:       payload.Repo.Name,
+		PRNumber:   int(payload.Number),
+		Title:      payload.PullRequest.Title,
+		Body:       payload.PullRequest.Body,
+		Branch:     payload.PullRequest.Head.Ref,
+		BaseBranch: payload.PullRequest.Base.Ref,
+		URL:        payload.PullRequest.HTMLURL,
 	}
 	baseUrl := constants.GITEA_URL
 	_, err := h.reviewService.ProcessPullRequest(baseUrl, context.Background(), prDetails)
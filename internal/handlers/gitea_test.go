@@ -17,7 +17,7 @@ import (
 )
 
 // createGiteaTestContext creates a mock Gin context for testing Gitea webhooks.
-func createGiteaTestContext(t *testing.T, payload []byte, secret string) (*gin.Context, *httptest.ResponseRecorder) {
+func createGiteaTestContext(t *testing.T, payload []byte, secret, eventType string) (*gin.Context, *httptest.ResponseRecorder) {
 	gin.SetMode(gin.TestMode)
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -30,7 +30,7 @@ func createGiteaTestContext(t *testing.T, payload []byte, secret string) (*gin.C
 	signature := hex.EncodeToString(mac.Sum(nil))
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Gitea-Event", "pull_request")
+	req.Header.Set("X-Gitea-Event", eventType)
 	req.Header.Set("X-Gitea-Signature", signature)
 	c.Request = req
 	return c, w
@@ -38,13 +38,13 @@ func createGiteaTestContext(t *testing.T, payload []byte, secret string) (*gin.C
 
 func TestGiteaWebhookHandler_Handle(t *testing.T) {
 	secret := "my-gitea-secret"
-	handler, err := NewGiteaWebhookHandler(nil, &config.Config{}, secret)
+	handler, err := NewGiteaWebhookHandler(nil, nil, &config.Config{}, secret)
 	assert.NoError(t, err)
 
 	t.Run("Success - Handles 'opened' pull request event", func(t *testing.T) {
 		payload := GiteaPullRequestHook{Action: "opened"}
 		jsonPayload, _ := json.Marshal(payload)
-		c, w := createGiteaTestContext(t, jsonPayload, secret)
+		c, w := createGiteaTestContext(t, jsonPayload, secret, "pull_request")
 
 		handler.Handle(c)
 
@@ -52,10 +52,34 @@ func TestGiteaWebhookHandler_Handle(t *testing.T) {
 		assert.Equal(t, "Event received.", w.Body.String())
 	})
 
-	t.Run("Success - Ignores 'closed' pull request event", func(t *testing.T) {
+	t.Run("Success - Handles 'closed' pull request event to collect feedback", func(t *testing.T) {
 		payload := GiteaPullRequestHook{Action: "closed"}
 		jsonPayload, _ := json.Marshal(payload)
-		c, w := createGiteaTestContext(t, jsonPayload, secret)
+		c, w := createGiteaTestContext(t, jsonPayload, secret, "pull_request")
+
+		handler.Handle(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Event received.", w.Body.String())
+	})
+
+	t.Run("Success - Ignores 'edited' pull request event", func(t *testing.T) {
+		payload := GiteaPullRequestHook{Action: "edited"}
+		jsonPayload, _ := json.Marshal(payload)
+		c, w := createGiteaTestContext(t, jsonPayload, secret, "pull_request")
+
+		handler.Handle(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Event ignored.", w.Body.String())
+	})
+
+	t.Run("Success - Ignores comments on issues", func(t *testing.T) {
+		var payload GiteaIssueCommentHook
+		payload.Action = "created"
+		payload.Comment.Body = "@ai-reviewer why?"
+		jsonPayload, _ := json.Marshal(payload)
+		c, w := createGiteaTestContext(t, jsonPayload, secret, "issue_comment")
 
 		handler.Handle(c)
 
@@ -64,7 +88,7 @@ func TestGiteaWebhookHandler_Handle(t *testing.T) {
 	})
 
 	t.Run("Failure - Invalid Signature", func(t *testing.T) {
-		c, w := createGiteaTestContext(t, []byte("{}"), "wrong-gitea-secret")
+		c, w := createGiteaTestContext(t, []byte("{}"), "wrong-gitea-secret", "pull_request")
 		handler.Handle(c)
 		assert.Equal(t, http.StatusForbidden, w.Code)
 	})
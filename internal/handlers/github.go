@@ -10,6 +10,7 @@ import (
 	"code-reviewer-bot/internal/models"
 	"code-reviewer-bot/internal/repository"
 	"code-reviewer-bot/internal/service"
+	"code-reviewer-bot/internal/storage"
 
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/gin-gonic/gin"
@@ -23,10 +24,10 @@ type GitHubWebhookHandler struct {
 }
 
 // NewGitHubWebhookHandler creates a new handler.
-func NewGitHubWebhookHandler(g *genkit.Genkit, cfg *config.Config, secret string) (*GitHubWebhookHandler, error) {
-	// The handler creates its own dependencies (repo and service).
+func NewGitHubWebhookHandler(g *genkit.Genkit, store storage.ReviewStore, cfg *config.Config, secret string) (*GitHubWebhookHandler, error) {
+	// The handler creates its own repo and service; the store is shared by all handlers.
 	repo := repository.NewGitHubRepository(context.Background(), cfg.VCS.GitHub.Token)
-	reviewService := service.NewReviewService(repo, g, cfg)
+	reviewService := service.NewReviewService(repo, store, g, cfg)
 	return &GitHubWebhookHandler{
 		reviewService: reviewService,
 		secret:        []byte(secret),
@@ -53,10 +54,50 @@ func (h *GitHubWebhookHandler) Handle(c *gin.Context) {
 		if action == constants.OPENED || action == constants.SYNCHRONIZE || action == constants.REOPENED {
 			log.Printf("Received GitHub PR event: %s for PR #%d", action, event.GetNumber())
 			go h.processPullRequest(event)
+		} else if action == constants.CLOSED {
+			// Reactions to the last round of comments arrive after its review ran.
+			go h.collectFeedback(event)
 		} else {
 			log.Printf("Ignoring GitHub PR action: %s", action)
 		}
 		c.String(http.StatusOK, "Event received.")
+	case *github.IssueCommentEvent:
+		comment := event.GetComment()
+		if event.GetAction() != constants.CREATED || !event.GetIssue().IsPullRequest() {
+			c.String(http.StatusOK, "Event ignored.")
+			return
+		}
+		h.handleComment(c, &models.Mention{
+			Owner:     event.GetRepo().GetOwner().GetLogin(),
+			Repo:      event.GetRepo().GetName(),
+			PRNumber:  event.GetIssue().GetNumber(),
+			CommentID: comment.GetID(),
+			Author:    comment.GetUser().GetLogin(),
+			Body:      comment.GetBody(),
+		})
+	case *github.PullRequestReviewCommentEvent:
+		comment := event.GetComment()
+		if event.GetAction() != constants.CREATED {
+			c.String(http.StatusOK, "Event ignored.")
+			return
+		}
+		threadID := comment.GetInReplyTo()
+		if threadID == 0 {
+			threadID = comment.GetID()
+		}
+		h.handleComment(c, &models.Mention{
+			Owner:     event.GetRepo().GetOwner().GetLogin(),
+			Repo:      event.GetRepo().GetName(),
+			PRNumber:  event.GetPullRequest().GetNumber(),
+			CommentID: comment.GetID(),
+			Author:    comment.GetUser().GetLogin(),
+			Body:      comment.GetBody(),
+			ThreadID:  threadID,
+			Path:      comment.GetPath(),
+			Line:      comment.GetLine(),
+			DiffHunk:  comment.GetDiffHunk(),
+			CommitID:  comment.GetCommitID(),
+		})
 	default:
 		log.Printf("Ignoring GitHub webhook event type: %T", event)
 		c.String(http.StatusOK, "Event type ignored.")
@@ -70,12 +111,14 @@ func (h *GitHubWebhookHandler) processPullRequest(event *github.PullRequestEvent
 		return
 	}
 	prDetails := &models.PRDetails{
-		Owner:    pr.Base.Repo.GetOwner().GetLogin(),
-		Repo:     pr.Base.Repo.GetName(),
-		PRNumber: event.GetNumber(),
-		Title:    pr.GetTitle(),
-		Branch:   pr.GetHead().GetRef(),
-		URL:      pr.GetHTMLURL(),
+		Owner:      pr.Base.Repo.GetOwner().GetLogin(),
+		Repo:       pr.Base.Repo.GetName(),
+		PRNumber:   event.GetNumber(),
+		Title:      pr.GetTitle(),
+		Body:       pr.GetBody(),
+		Branch:     pr.GetHead().GetRef(),
+		BaseBranch: pr.GetBase().GetRef(),
+		URL:        pr.GetHTMLURL(),
 	}
 	baseUrl := constants.GITHUB_URL
 	_, err := h.reviewService.ProcessPullRequest(baseUrl, context.Background(), prDetails)
@@ -84,3 +127,42 @@ func (h *GitHubWebhookHandler) processPullRequest(event *github.PullRequestEvent
 
 	}
 }
+
+func (h *GitHubWebhookHandler) collectFeedback(event *github.PullRequestEvent) {
+	prDetails := &models.PRDetails{
+		Owner:    event.GetRepo().GetOwner().GetLogin(),
+		Repo:     event.GetRepo().GetName(),
+		PRNumber: event.GetNumber(),
+	}
+	if err := h.reviewService.CollectFeedback(context.Background(), prDetails); err != nil {
+		log.Printf("Collecting feedback failed for GitHub PR #%d: %v", prDetails.PRNumber, err)
+	}
+}
+
+// handleComment runs the slash commands of a new PR comment or, if it has none,
+// answers it when it mentions the bot.
+func (h *GitHubWebhookHandler) handleComment(c *gin.Context, comment *models.Mention) {
+	if commands := h.reviewService.ParseCommands(c.Request.Context(), comment.Author, comment.Body); len(commands) > 0 {
+		log.Printf("Received GitHub commands in PR #%d", comment.PRNumber)
+		go h.runCommands(comment, commands)
+	} else if h.reviewService.MentionsBot(c.Request.Context(), comment.Author, comment.Body) {
+		log.Printf("Received GitHub mention in PR #%d", comment.PRNumber)
+		go h.replyToMention(comment)
+	} else {
+		c.String(http.StatusOK, "Event ignored.")
+		return
+	}
+	c.String(http.StatusOK, "Event received.")
+}
+
+func (h *GitHubWebhookHandler) runCommands(comment *models.Mention, commands []service.Command) {
+	if err := h.reviewService.RunCommands(constants.GITHUB_URL, context.Background(), comment, commands); err != nil {
+		log.Printf("Commands failed for GitHub PR #%d: %v", comment.PRNumber, err)
+	}
+}
+
+func (h *GitHubWebhookHandler) replyToMention(mention *models.Mention) {
+	if err := h.reviewService.ReplyToMention(context.Background(), mention); err != nil {
+		log.Printf("Reply failed for GitHub PR #%d: %v", mention.PRNumber, err)
+	}
+}
@@ -41,7 +41,7 @@ func TestGitHubWebhookHandler_Handle(t *testing.T) {
 	secret := "my-super-secret-key"
 	// For these unit tests, we can pass nil for Genkit and an empty config
 	// because we are only testing the handler's routing logic, not the full service call.
-	handler, err := NewGitHubWebhookHandler(nil, &config.Config{}, secret)
+	handler, err := NewGitHubWebhookHandler(nil, nil, &config.Config{}, secret)
 	assert.NoError(t, err)
 
 	t.Run("Success - Handles 'opened' pull request event", func(t *testing.T) {
@@ -66,6 +66,51 @@ func TestGitHubWebhookHandler_Handle(t *testing.T) {
 		assert.Equal(t, "Event received.", w.Body.String())
 	})
 
+	t.Run("Success - Ignores comments that do not mention the bot", func(t *testing.T) {
+		payload := github.IssueCommentEvent{
+			Action:  github.String("created"),
+			Issue:   &github.Issue{Number: github.Int(1), PullRequestLinks: &github.PullRequestLinks{}},
+			Comment: &github.IssueComment{Body: github.String("@ai-reviewer why?"), User: &github.User{Login: github.String("bob")}},
+		}
+		jsonPayload, _ := json.Marshal(payload)
+		c, w := createGitHubTestContext(t, jsonPayload, secret, "issue_comment")
+
+		// Conversation is disabled in this handler's config.
+		handler.Handle(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Event ignored.", w.Body.String())
+	})
+
+	t.Run("Success - Ignores commands while they are disabled", func(t *testing.T) {
+		payload := github.IssueCommentEvent{
+			Action:  github.String("created"),
+			Issue:   &github.Issue{Number: github.Int(1), PullRequestLinks: &github.PullRequestLinks{}},
+			Comment: &github.IssueComment{Body: github.String("/pause"), User: &github.User{Login: github.String("bob")}},
+		}
+		jsonPayload, _ := json.Marshal(payload)
+		c, w := createGitHubTestContext(t, jsonPayload, secret, "issue_comment")
+
+		handler.Handle(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Event ignored.", w.Body.String())
+	})
+
+	t.Run("Success - Ignores edited review comments", func(t *testing.T) {
+		payload := github.PullRequestReviewCommentEvent{
+			Action:  github.String("edited"),
+			Comment: &github.PullRequestComment{Body: github.String("@ai-reviewer why?")},
+		}
+		jsonPayload, _ := json.Marshal(payload)
+		c, w := createGitHubTestContext(t, jsonPayload, secret, "pull_request_review_comment")
+
+		handler.Handle(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Event ignored.", w.Body.String())
+	})
+
 	t.Run("Failure - Invalid Signature", func(t *testing.T) {
 		c, w := createGitHubTestContext(t, []byte("{}"), "wrong-secret", "pull_request")
 		handler.Handle(c)
@@ -6,6 +6,7 @@ import (
 
 	"code-reviewer-bot/config"
 	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/storage"
 
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/gin-gonic/gin"
@@ -23,7 +24,7 @@ type ProviderConfig struct {
 	TokenEnvVar         string
 	WebhookSecretEnvVar string
 	// NewHandlerFunc is a factory function that creates the specific handler.
-	NewHandlerFunc func(g *genkit.Genkit, cfg *config.Config, secret string) (WebhookHandler, error)
+	NewHandlerFunc func(g *genkit.Genkit, store storage.ReviewStore, cfg *config.Config, secret string) (WebhookHandler, error)
 }
 
 // AllProviders is a slice containing the configuration for all supported VCS providers.
@@ -33,9 +34,9 @@ var AllProviders = []ProviderConfig{
 		Endpoint:            constants.GITHUB_ENDPOINT, // Grouped under /api
 		TokenEnvVar:         constants.GITHUB_TOKEN,
 		WebhookSecretEnvVar: constants.GITHUB_WEBHOOK_SECRET,
-		NewHandlerFunc: func(g *genkit.Genkit, cfg *config.Config, secret string) (WebhookHandler, error) {
+		NewHandlerFunc: func(g *genkit.Genkit, store storage.ReviewStore, cfg *config.Config, secret string) (WebhookHandler, error) {
 			// This type assertion is safe because NewGitHubWebhookHandler returns a type that satisfies the interface.
-			return NewGitHubWebhookHandler(g, cfg, secret)
+			return NewGitHubWebhookHandler(g, store, cfg, secret)
 		},
 	},
 	{
@@ -43,15 +44,16 @@ var AllProviders = []ProviderConfig{
 		Endpoint:            constants.GITEA_ENDPOINT, // Grouped under /api
 		TokenEnvVar:         constants.GITEA_TOKEN,
 		WebhookSecretEnvVar: constants.GITEA_WEBHOOK_SECRET,
-		NewHandlerFunc: func(g *genkit.Genkit, cfg *config.Config, secret string) (WebhookHandler, error) {
-			return NewGiteaWebhookHandler(g, cfg, secret)
+		NewHandlerFunc: func(g *genkit.Genkit, store storage.ReviewStore, cfg *config.Config, secret string) (WebhookHandler, error) {
+			return NewGiteaWebhookHandler(g, store, cfg, secret)
 		},
 	},
 }
 
 // RegisterHandlers iterates through all defined providers and dynamically registers their webhook
-// handlers with the Gin router if their required secrets are present in the environment.
-func RegisterHandlers(router *gin.Engine, g *genkit.Genkit, cfg *config.Config) {
+// handlers with the Gin router if their required secrets are present in the environment. All
+// handlers share the given review store.
+func RegisterHandlers(router *gin.Engine, g *genkit.Genkit, store storage.ReviewStore, cfg *config.Config) {
 	// Group all webhook handlers under a common API path for better organization.
 	apiGroup := router.Group("/api")
 
@@ -62,7 +64,7 @@ func RegisterHandlers(router *gin.Engine, g *genkit.Genkit, cfg *config.Config)
 		// Only activate the handler if both its token and secret are found.
 		if token != "" && secret != "" {
 			log.Printf("%s credentials found. Initializing handler...", provider.Name)
-			handler, err := provider.NewHandlerFunc(g, cfg, secret)
+			handler, err := provider.NewHandlerFunc(g, store, cfg, secret)
 			if err != nil {
 				log.Printf("WARNING: Could not create %s webhook handler: %v", provider.Name, err)
 				continue
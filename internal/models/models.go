@@ -8,22 +8,96 @@ type PRDetails struct {
 	Repo     string
 	PRNumber int
 	Title    string
-	Branch   string
-	URL      string
+	// Body is the PR description written by the author.
+	Body   string
+	Branch string
+	// BaseBranch is the branch the PR merges into; empty means the default branch.
+	BaseBranch string
+	URL        string
+}
+
+// Issue holds the title and description of an issue a pull request refers to.
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+}
+
+// ThreadComment is a comment already posted in a PR conversation or review thread.
+type ThreadComment struct {
+	ID     int64
+	Author string
+	Body   string
+}
+
+// Mention is a PR comment that addresses the bot, by name or with a slash command.
+type Mention struct {
+	Owner     string
+	Repo      string
+	PRNumber  int
+	CommentID int64
+	Author    string
+	Body      string
+	// ThreadID is the first comment of the review thread the mention belongs to;
+	// 0 for comments in the PR conversation.
+	ThreadID int64
+	// Path, Line, DiffHunk and CommitID locate a comment on a diff line.
+	Path     string
+	Line     int
+	DiffHunk string
+	CommitID string
+}
+
+// PostedComment is a line comment of a pull request with the reactions and replies
+// it received.
+type PostedComment struct {
+	ID         int64
+	Path       string
+	Body       string
+	ThumbsUp   int
+	ThumbsDown int
+	// Replies are the later comments of its thread, oldest first.
+	Replies []*ThreadComment
+}
+
+// CommentFeedback is the developers' verdict on a line comment posted by the bot.
+type CommentFeedback struct {
+	Path string
+	// Body is the posted text, which identifies the stored PRComment.
+	Body string
+	// Verdict is constants.FEEDBACK_ACCEPTED or constants.FEEDBACK_REJECTED.
+	Verdict string
+	// Reason is the reaction or the reply that gave the verdict.
+	Reason string
+}
+
+// FeedbackStats counts the comments posted in one category and how many of them
+// developers rejected.
+type FeedbackStats struct {
+	Category string
+	Posted   int
+	Rejected int
 }
 
 // Comment represents a single review comment to be posted.
 type Comment struct {
 	Body     string
 	Path     string
-	Position int // For GitHub and Gitea's review endpoint
-	Line     int // For Gitea's fallback line comment endpoint
+	Position int    // For GitHub and Gitea's review endpoint
+	Line     int    // For Gitea's fallback line comment endpoint
+	Model    string // The LLM that produced this comment
+	Severity string
+	Category string
 }
 
 // ReviewComment represents the structured response from the LLM.
 type ReviewComment struct {
 	LineContent string `json:"line_content"`
 	Message     string `json:"message"`
+	Severity    string `json:"severity"`
+	Category    string `json:"category"`
+	// Suggestion optionally replaces the commented line with corrected code.
+	Suggestion string `json:"suggestion,omitempty"`
 }
 
 // DiffChunk represents a single block of changes in a diff.
@@ -42,10 +116,35 @@ type ArchitectureReviewResponse struct {
 	NeedsComment  bool                `json:"needs_comment"`
 }
 
+// MissingTest is a changed function the test checker found no test for.
+type MissingTest struct {
+	Language string `json:"language"`
+	File     string `json:"file"`
+	Function string `json:"function"`
+}
+
 type TestReviewResponse struct {
-	MissingTests []string  `json:"missing_tests"`
-	Comments     []Comment `json:"comments"`
-	HasMissing   bool      `json:"has_missing"`
+	MissingTests []MissingTest `json:"missing_tests"`
+	Comments     []Comment     `json:"comments"`
+	HasMissing   bool          `json:"has_missing"`
+}
+
+// TokenUsage aggregates LLM token consumption and its estimated cost in USD.
+type TokenUsage struct {
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+}
+
+// ReviewResult collects the outcome of a single review run for persistence.
+type ReviewResult struct {
+	Status   string
+	Comments []*Comment
+	Usage    TokenUsage
+	// Discarded is the number of findings the verifier rejected.
+	Discarded int
+	// PromptVersion lists the prompt revisions used, e.g. "review@2, verification@1".
+	PromptVersion string
 }
 
 type Project struct {
@@ -64,7 +163,15 @@ type PullRequest struct {
 	Status     string
 	ReviewedAt time.Time
 	PrURL      string
-	Project    Project `gorm:"foreignKey:ProjectID"`
+	// Token usage and estimated cost of this review.
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	// DiscardedCount is the number of findings the verifier rejected.
+	DiscardedCount int
+	// PromptVersion lists the prompt revisions used, so prompt changes can be compared.
+	PromptVersion string  `gorm:"size:255"`
+	Project       Project `gorm:"foreignKey:ProjectID"`
 }
 
 type ReviewStats struct {
@@ -73,6 +180,10 @@ type ReviewStats struct {
 	SuccessCount int
 	FailedCount  int
 	TotalCount   int
+	// Cumulative token usage and estimated cost across all reviews.
+	InputTokens  int64
+	OutputTokens int64
+	TotalCost    float64
 	UpdatedAt    time.Time
 	Project      Project `gorm:"foreignKey:ProjectID"`
 }
@@ -86,6 +197,24 @@ type PRComment struct {
 	CommentText string `gorm:"not null"`
 	CommentType string `gorm:"size:50"`
 	Severity    string `gorm:"size:20"`
+	ModelName   string `gorm:"size:100"`
 	CreatedAt   time.Time
 	Resolved    bool
+	// Feedback is the developers' verdict, "accepted" or "rejected"; empty until
+	// someone reacts or replies.
+	Feedback       string `gorm:"size:20;index"`
+	FeedbackReason string `gorm:"size:512"`
+}
+
+// PRState holds the settings of one pull request changed through slash commands.
+type PRState struct {
+	ID        uint `gorm:"primaryKey"`
+	ProjectID uint `gorm:"uniqueIndex:idx_pr_state_project_pr;not null"`
+	PRNumber  int  `gorm:"uniqueIndex:idx_pr_state_project_pr;not null"`
+	// Paused stops automatic reviews; reviews asked for with /review still run.
+	Paused bool
+	// IgnoredPaths are globs of files left out of every review of the pull request.
+	IgnoredPaths []string `gorm:"serializer:json"`
+	UpdatedAt    time.Time
+	Project      Project `gorm:"foreignKey:ProjectID"`
 }
@@ -0,0 +1,144 @@
+// Package prompts resolves the dotprompt files that drive every LLM call. The files
+// are registered with Genkit at startup (genkit.WithPromptDir); this package adds
+// the version identifier declared in each file's front-matter.
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"gopkg.in/yaml.v3"
+)
+
+// Prompt is a registered dotprompt together with its declared version.
+type Prompt struct {
+	Name    string
+	Version string
+	prompt  *ai.Prompt
+}
+
+// ID identifies the prompt revision, e.g. "review@2".
+func (p *Prompt) ID() string {
+	return p.Name + "@" + p.Version
+}
+
+// Render renders the prompt for the given input into a model request. Model, config
+// and output schema come from the prompt's front-matter.
+func (p *Prompt) Render(ctx context.Context, input any) (*ai.GenerateActionOptions, error) {
+	opts, err := p.prompt.Render(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render prompt %s: %w", p.ID(), err)
+	}
+	return opts, nil
+}
+
+// Set looks up prompts registered from a directory.
+type Set struct {
+	g   *genkit.Genkit
+	dir string
+
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+func NewSet(g *genkit.Genkit, dir string) *Set {
+	return &Set{g: g, dir: dir, versions: make(map[string]string)}
+}
+
+// Get returns the prompt with the given name, such as "review" or the variant
+// "review.sql" loaded from review.sql.prompt.
+func (s *Set) Get(name string) (*Prompt, error) {
+	p := genkit.LookupPrompt(s.g, name)
+	if p == nil {
+		return nil, fmt.Errorf("prompt %q is not registered (is %s/%s.prompt valid?)", name, s.dir, name)
+	}
+	version, err := s.version(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Prompt{Name: name, Version: version, prompt: p}, nil
+}
+
+func (s *Set) version(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.versions[name]; ok {
+		return v, nil
+	}
+	v, err := ReadVersion(filepath.Join(s.dir, name+".prompt"))
+	if err != nil {
+		return "", err
+	}
+	s.versions[name] = v
+	return v, nil
+}
+
+// frontMatter holds the front-matter keys this package reads.
+type frontMatter struct {
+	Version string `yaml:"version"`
+	Model   string `yaml:"model"`
+}
+
+// ReadVersion returns the "version" declared in a prompt file's front-matter.
+func ReadVersion(path string) (string, error) {
+	meta, err := readFrontMatter(path)
+	if err != nil {
+		return "", err
+	}
+	if meta.Version == "" {
+		return "", fmt.Errorf("prompt file %s does not declare a version", path)
+	}
+	return meta.Version, nil
+}
+
+// Models returns the models named by the "model" key of the prompt files in dir
+// and its subdirectories, without duplicates. Partials, whose names start with
+// "_", have no front-matter and are skipped.
+func Models(dir string) ([]string, error) {
+	var models []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".prompt" || strings.HasPrefix(d.Name(), "_") {
+			return nil
+		}
+		meta, err := readFrontMatter(path)
+		if err != nil {
+			return err
+		}
+		if meta.Model != "" && !slices.Contains(models, meta.Model) {
+			models = append(models, meta.Model)
+		}
+		return nil
+	})
+	return models, err
+}
+
+func readFrontMatter(path string) (*frontMatter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	source := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(source, "---\n") {
+		return nil, fmt.Errorf("prompt file %s has no front-matter", path)
+	}
+	text, _, found := strings.Cut(source[len("---\n"):], "\n---")
+	if !found {
+		return nil, fmt.Errorf("prompt file %s has unterminated front-matter", path)
+	}
+	var meta frontMatter
+	if err := yaml.Unmarshal([]byte(text), &meta); err != nil {
+		return nil, fmt.Errorf("invalid front-matter in %s: %w", path, err)
+	}
+	return &meta, nil
+}
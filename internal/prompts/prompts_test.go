@@ -0,0 +1,98 @@
+package prompts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+)
+
+const promptDir = "../../config/prompts"
+
+func TestSet_Get(t *testing.T) {
+	ctx := context.Background()
+	g, err := genkit.Init(ctx, genkit.WithPromptDir(promptDir))
+	assert.NoError(t, err)
+	set := NewSet(g, promptDir)
+
+	t.Run("Success - renders the shipped architecture prompt", func(t *testing.T) {
+		p, err := set.Get("architecture")
+		assert.NoError(t, err)
+		assert.Equal(t, "architecture@"+p.Version, p.ID())
+
+		req, err := p.Render(ctx, map[string]any{"summary": "Total directories: 3", "score": 4, "missingLayers": []string{"Tests", "Data Access"}})
+		assert.NoError(t, err)
+		text := req.Messages[len(req.Messages)-1].Text()
+		assert.Contains(t, text, "Architecture Score: 4/10")
+		assert.Contains(t, text, "Missing Layers: Tests, Data Access")
+		assert.NotNil(t, req.Output.JsonSchema)
+	})
+
+	t.Run("Success - renders the shipped verification prompt", func(t *testing.T) {
+		p, err := set.Get("verification")
+		assert.NoError(t, err)
+		req, err := p.Render(ctx, map[string]any{"boundary": "untrusted-test", "filePath": "a.go", "codeSnippet": "+x := a < b", "lineContent": "+x := a < b", "message": "m"})
+		assert.NoError(t, err)
+		assert.Contains(t, req.Messages[len(req.Messages)-1].Text(), "Commented line: <untrusted-test>+x := a < b</untrusted-test>")
+		assert.EqualValues(t, 0.0, req.Config.(map[string]any)["temperature"])
+	})
+
+	t.Run("Failure - unknown prompt", func(t *testing.T) {
+		_, err := set.Get("missing")
+		assert.ErrorContains(t, err, `prompt "missing" is not registered`)
+	})
+}
+
+func TestReadVersion(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	t.Run("Success - reads the front-matter version", func(t *testing.T) {
+		v, err := ReadVersion(write("ok.prompt", "---\nversion: \"7\"\nconfig:\n  temperature: 0.1\n---\nHello\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, "7", v)
+	})
+
+	t.Run("Failure - no front-matter", func(t *testing.T) {
+		_, err := ReadVersion(write("plain.prompt", "Hello\n"))
+		assert.ErrorContains(t, err, "has no front-matter")
+	})
+
+	t.Run("Failure - unterminated front-matter", func(t *testing.T) {
+		_, err := ReadVersion(write("open.prompt", "---\nversion: 1\nHello\n"))
+		assert.ErrorContains(t, err, "unterminated front-matter")
+	})
+}
+
+func TestModels(t *testing.T) {
+	t.Run("Success - lists the models named in front-matter", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, "sql"), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "review.prompt"), []byte("---\nversion: \"1\"\nmodel: fake/reviewer\n---\nHi\n"), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "sql", "review.sql.prompt"), []byte("---\nversion: \"1\"\nmodel: fake/sql\n---\nHi\n"), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "summary.prompt"), []byte("---\nversion: \"1\"\nmodel: fake/reviewer\n---\nHi\n"), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "_partial.prompt"), []byte("Hi\n"), 0o644))
+
+		models, err := Models(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"fake/reviewer", "fake/sql"}, models)
+	})
+
+	t.Run("Success - the shipped prompts use the configured chain", func(t *testing.T) {
+		models, err := Models(promptDir)
+		assert.NoError(t, err)
+		assert.Empty(t, models)
+	})
+
+	t.Run("Failure - missing directory", func(t *testing.T) {
+		_, err := Models(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
@@ -12,4 +12,28 @@ type VcsRepository interface {
 	GetPRCommitID(ctx context.Context, owner, repo string, prNumber int) (string, error)
 	PostReview(ctx context.Context, owner, repo string, prNumber int, comments []*models.Comment, commitID string) error
 	PostGeneralComment(ctx context.Context, owner, repo string, prNumber int, body string) error
+	// GetPullRequest returns the PR's title, description, branches and URL.
+	GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*models.PRDetails, error)
+	// GetIssue returns the title and description of an issue in the repository.
+	GetIssue(ctx context.Context, owner, repo string, number int) (*models.Issue, error)
+	// FindCommentByMarker returns the ID of the first general PR comment written by
+	// the bot's own account that contains marker, or 0 when there is none.
+	FindCommentByMarker(ctx context.Context, owner, repo string, prNumber int, marker string) (int64, error)
+	// UpdateGeneralComment replaces the body of a general PR comment.
+	UpdateGeneralComment(ctx context.Context, owner, repo string, commentID int64, body string) error
+	// ListThreadComments returns the comments of a review thread, oldest first. A
+	// threadID of 0 selects the PR conversation.
+	ListThreadComments(ctx context.Context, owner, repo string, prNumber int, threadID int64) ([]*models.ThreadComment, error)
+	// ReplyToThread answers in a review thread, or in the PR conversation when
+	// threadID is 0.
+	ReplyToThread(ctx context.Context, owner, repo string, prNumber int, threadID int64, body string) error
+	// GetFileContent returns a file's content at the given commit or branch.
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	// ListPostedComments returns the line comments of a pull request that start a
+	// thread, with their reactions and the replies of the thread.
+	ListPostedComments(ctx context.Context, owner, repo string, prNumber int) ([]*models.PostedComment, error)
+	// AuthenticatedLogin returns the login of the account the bot's token belongs to.
+	AuthenticatedLogin(ctx context.Context) (string, error)
+	// CanWrite reports whether user may push to the repository.
+	CanWrite(ctx context.Context, owner, repo, user string) (bool, error)
 }
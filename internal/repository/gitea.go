@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
+	"sync"
 
 	"code-reviewer-bot/internal/models"
 
@@ -14,6 +16,11 @@ import (
 // GiteaRepository implements the VcsRepository interface for Gitea.
 type GiteaRepository struct {
 	client *gitea.Client
+	// loginOnce looks up the authenticated account on first use; the login, or the
+	// error of a token that cannot read its own user, is kept for every later call.
+	loginOnce sync.Once
+	login     string
+	loginErr  error
 }
 
 // NewGiteaRepository creates a new client for interacting with the Gitea API.
@@ -70,3 +77,182 @@ func (g *GiteaRepository) PostGeneralComment(ctx context.Context, owner, repo st
 	_, _, err := g.client.CreateIssueComment(owner, repo, int64(prIndex), opts)
 	return err
 }
+
+func (g *GiteaRepository) GetPullRequest(ctx context.Context, owner, repo string, prIndex int) (*models.PRDetails, error) {
+	pr, _, err := g.client.GetPullRequest(owner, repo, int64(prIndex))
+	if err != nil {
+		return nil, err
+	}
+	details := &models.PRDetails{
+		Owner:    owner,
+		Repo:     repo,
+		PRNumber: prIndex,
+		Title:    pr.Title,
+		Body:     pr.Body,
+		URL:      pr.HTMLURL,
+	}
+	if pr.Head != nil {
+		details.Branch = pr.Head.Ref
+	}
+	if pr.Base != nil {
+		details.BaseBranch = pr.Base.Ref
+	}
+	return details, nil
+}
+
+func (g *GiteaRepository) GetIssue(ctx context.Context, owner, repo string, index int) (*models.Issue, error) {
+	issue, _, err := g.client.GetIssue(owner, repo, int64(index))
+	if err != nil {
+		return nil, err
+	}
+	return &models.Issue{Number: index, Title: issue.Title, Body: issue.Body}, nil
+}
+
+func (g *GiteaRepository) FindCommentByMarker(ctx context.Context, owner, repo string, prIndex int, marker string) (int64, error) {
+	login, err := g.AuthenticatedLogin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	opts := gitea.ListIssueCommentOptions{ListOptions: gitea.ListOptions{Page: 1, PageSize: 50}}
+	for {
+		comments, resp, err := g.client.ListIssueComments(owner, repo, int64(prIndex), opts)
+		if err != nil {
+			return 0, err
+		}
+		for _, c := range comments {
+			if strings.Contains(c.Body, marker) && c.Poster != nil && strings.EqualFold(c.Poster.UserName, login) {
+				return c.ID, nil
+			}
+		}
+		if resp == nil || resp.NextPage == 0 || len(comments) == 0 {
+			return 0, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// AuthenticatedLogin returns the login of the token's account. The lookup is made
+// once per client, whether it succeeds or not.
+func (g *GiteaRepository) AuthenticatedLogin(ctx context.Context) (string, error) {
+	g.loginOnce.Do(func() {
+		user, _, err := g.client.GetMyUserInfo()
+		if err != nil {
+			g.loginErr = fmt.Errorf("failed to get the authenticated user: %w", err)
+			return
+		}
+		g.login = user.UserName
+	})
+	return g.login, g.loginErr
+}
+
+// CanWrite reports whether user has at least write access to the repository.
+func (g *GiteaRepository) CanWrite(ctx context.Context, owner, repo, user string) (bool, error) {
+	perm, _, err := g.client.CollaboratorPermission(owner, repo, user)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the permission of %s: %w", user, err)
+	}
+	switch perm.Permission {
+	case gitea.AccessModeWrite, gitea.AccessModeAdmin, gitea.AccessModeOwner:
+		return true, nil
+	}
+	return false, nil
+}
+
+func (g *GiteaRepository) UpdateGeneralComment(ctx context.Context, owner, repo string, commentID int64, body string) error {
+	_, _, err := g.client.EditIssueComment(owner, repo, commentID, gitea.EditIssueCommentOption{Body: body})
+	return err
+}
+
+// ListThreadComments returns the PR conversation. Gitea's API does not expose review
+// threads, so threadID is ignored.
+func (g *GiteaRepository) ListThreadComments(ctx context.Context, owner, repo string, prIndex int, threadID int64) ([]*models.ThreadComment, error) {
+	var thread []*models.ThreadComment
+	opts := gitea.ListIssueCommentOptions{ListOptions: gitea.ListOptions{Page: 1, PageSize: 50}}
+	for {
+		comments, resp, err := g.client.ListIssueComments(owner, repo, int64(prIndex), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			author := ""
+			if c.Poster != nil {
+				author = c.Poster.UserName
+			}
+			thread = append(thread, &models.ThreadComment{ID: c.ID, Author: author, Body: c.Body})
+		}
+		if resp == nil || resp.NextPage == 0 || len(comments) == 0 {
+			return thread, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ReplyToThread posts the reply in the PR conversation; Gitea's API cannot reply
+// inside a review thread.
+func (g *GiteaRepository) ReplyToThread(ctx context.Context, owner, repo string, prIndex int, threadID int64, body string) error {
+	return g.PostGeneralComment(ctx, owner, repo, prIndex, body)
+}
+
+func (g *GiteaRepository) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	data, _, err := g.client.GetFile(owner, repo, ref, path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ListPostedComments collects the comments of every review of the pull request.
+// Gitea does not link replies to the comment they answer, so later comments on the
+// same line are taken as replies to the first one.
+func (g *GiteaRepository) ListPostedComments(ctx context.Context, owner, repo string, prIndex int) ([]*models.PostedComment, error) {
+	var comments []*gitea.PullReviewComment
+	opts := gitea.ListPullReviewsOptions{ListOptions: gitea.ListOptions{Page: 1, PageSize: 50}}
+	for {
+		reviews, resp, err := g.client.ListPullReviews(owner, repo, int64(prIndex), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, review := range reviews {
+			reviewComments, _, err := g.client.ListPullReviewComments(owner, repo, int64(prIndex), review.ID)
+			if err != nil {
+				return nil, err
+			}
+			comments = append(comments, reviewComments...)
+		}
+		if resp == nil || resp.NextPage == 0 || len(reviews) == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	slices.SortStableFunc(comments, func(a, b *gitea.PullReviewComment) int { return a.Created.Compare(b.Created) })
+
+	var posted []*models.PostedComment
+	threads := make(map[string]*models.PostedComment)
+	for _, c := range comments {
+		key := fmt.Sprintf("%s:%d", c.Path, c.LineNum)
+		if thread := threads[key]; thread != nil {
+			author := ""
+			if c.Reviewer != nil {
+				author = c.Reviewer.UserName
+			}
+			thread.Replies = append(thread.Replies, &models.ThreadComment{ID: c.ID, Author: author, Body: c.Body})
+			continue
+		}
+		pc := &models.PostedComment{ID: c.ID, Path: c.Path, Body: c.Body}
+		reactions, _, err := g.client.GetIssueCommentReactions(owner, repo, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reactions {
+			switch r.Reaction {
+			case "+1":
+				pc.ThumbsUp++
+			case "-1":
+				pc.ThumbsDown++
+			}
+		}
+		threads[key] = pc
+		posted = append(posted, pc)
+	}
+	return posted, nil
+}
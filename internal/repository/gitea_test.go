@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"code.gitea.io/sdk/gitea"
 	"github.com/stretchr/testify/assert"
@@ -124,3 +125,177 @@ func TestGiteaClient_PostGeneralComment(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestGiteaClient_GetPullRequest(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client, mux, server := setupGiteaTestServer(t)
+		defer server.Close()
+
+		mux.HandleFunc("/api/v1/repos/owner/repo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+			resp := gitea.PullRequest{
+				Title:   "Add retries",
+				Body:    "Fixes #3",
+				HTMLURL: "https://gitea.com/owner/repo/pulls/1",
+				Head:    &gitea.PRBranchInfo{Ref: "feature"},
+				Base:    &gitea.PRBranchInfo{Ref: "main"},
+			}
+			json.NewEncoder(w).Encode(resp)
+		})
+
+		pr, err := client.GetPullRequest(context.Background(), "owner", "repo", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.PRDetails{
+			Owner: "owner", Repo: "repo", PRNumber: 1,
+			Title: "Add retries", Body: "Fixes #3",
+			Branch: "feature", BaseBranch: "main",
+			URL: "https://gitea.com/owner/repo/pulls/1",
+		}, pr)
+	})
+}
+
+func TestGiteaClient_GetIssue(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client, mux, server := setupGiteaTestServer(t)
+		defer server.Close()
+
+		mux.HandleFunc("/api/v1/repos/owner/repo/issues/3", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(gitea.Issue{Title: "Requests time out", Body: "Retry on 503."})
+		})
+
+		issue, err := client.GetIssue(context.Background(), "owner", "repo", 3)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.Issue{Number: 3, Title: "Requests time out", Body: "Retry on 503."}, issue)
+	})
+
+	t.Run("Failure - Not Found", func(t *testing.T) {
+		client, _, server := setupGiteaTestServer(t)
+		defer server.Close()
+
+		_, err := client.GetIssue(context.Background(), "owner", "repo", 3)
+		assert.Error(t, err)
+	})
+}
+
+func TestGiteaClient_FindCommentByMarker(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client, mux, server := setupGiteaTestServer(t)
+		defer server.Close()
+
+		mux.HandleFunc("/api/v1/user", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(gitea.User{UserName: "review-bot"})
+		})
+		mux.HandleFunc("/api/v1/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]*gitea.Comment{
+				{ID: 7, Body: "LGTM", Poster: &gitea.User{UserName: "bob"}},
+				{ID: 8, Body: "<!-- marker --> posted first", Poster: &gitea.User{UserName: "mallory"}},
+				{ID: 42, Body: "<!-- marker -->\nSummary", Poster: &gitea.User{UserName: "review-bot"}},
+			})
+		})
+
+		id, err := client.FindCommentByMarker(context.Background(), "owner", "repo", 1, "<!-- marker -->")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), id)
+
+		id, err = client.FindCommentByMarker(context.Background(), "owner", "repo", 1, "<!-- other -->")
+		assert.NoError(t, err)
+		assert.Zero(t, id)
+	})
+}
+
+func TestGiteaClient_UpdateGeneralComment(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client, mux, server := setupGiteaTestServer(t)
+		defer server.Close()
+
+		mux.HandleFunc("/api/v1/repos/owner/repo/issues/comments/42", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"body": "Updated"}`, string(body))
+			fmt.Fprint(w, `{}`)
+		})
+
+		err := client.UpdateGeneralComment(context.Background(), "owner", "repo", 42, "Updated")
+		assert.NoError(t, err)
+	})
+}
+
+func TestGiteaClient_CanWrite(t *testing.T) {
+	client, mux, server := setupGiteaTestServer(t)
+	defer server.Close()
+	mux.HandleFunc("/api/v1/repos/owner/repo/collaborators/alice/permission", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"permission": "owner"}`)
+	})
+	mux.HandleFunc("/api/v1/repos/owner/repo/collaborators/bob/permission", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"permission": "read"}`)
+	})
+
+	t.Run("Success - repository owner", func(t *testing.T) {
+		ok, err := client.CanWrite(context.Background(), "owner", "repo", "alice")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Failure - read access", func(t *testing.T) {
+		ok, err := client.CanWrite(context.Background(), "owner", "repo", "bob")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestGiteaClient_ListThreadComments(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client, mux, server := setupGiteaTestServer(t)
+		defer server.Close()
+
+		mux.HandleFunc("/api/v1/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]*gitea.Comment{{ID: 7, Body: "@ai-reviewer why?", Poster: &gitea.User{UserName: "bob"}}})
+		})
+
+		thread, err := client.ListThreadComments(context.Background(), "owner", "repo", 1, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.ThreadComment{{ID: 7, Author: "bob", Body: "@ai-reviewer why?"}}, thread)
+	})
+}
+
+func TestGiteaClient_GetFileContent(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client, mux, server := setupGiteaTestServer(t)
+		defer server.Close()
+
+		mux.HandleFunc("/api/v1/repos/owner/repo/raw/main.go", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "abc123", r.URL.Query().Get("ref"))
+			fmt.Fprint(w, "package main\n")
+		})
+
+		content, err := client.GetFileContent(context.Background(), "owner", "repo", "main.go", "abc123")
+		assert.NoError(t, err)
+		assert.Equal(t, "package main\n", content)
+	})
+}
+
+func TestGiteaClient_ListPostedComments(t *testing.T) {
+	t.Run("Success - later comments on a line are replies", func(t *testing.T) {
+		client, mux, server := setupGiteaTestServer(t)
+		defer server.Close()
+
+		created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		mux.HandleFunc("/api/v1/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]*gitea.PullReview{{ID: 5}, {ID: 6}})
+		})
+		mux.HandleFunc("/api/v1/repos/owner/repo/pulls/1/reviews/5/comments", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]*gitea.PullReviewComment{{ID: 10, Path: "main.go", LineNum: 3, Body: "Possible nil dereference.", Created: created}})
+		})
+		mux.HandleFunc("/api/v1/repos/owner/repo/pulls/1/reviews/6/comments", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]*gitea.PullReviewComment{{ID: 12, Path: "main.go", LineNum: 3, Body: "Not an issue.",
+				Reviewer: &gitea.User{UserName: "bob"}, Created: created.Add(time.Hour)}})
+		})
+		mux.HandleFunc("/api/v1/repos/owner/repo/issues/comments/10/reactions", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]*gitea.Reaction{{Reaction: "-1"}, {Reaction: "heart"}})
+		})
+
+		posted, err := client.ListPostedComments(context.Background(), "owner", "repo", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.PostedComment{{ID: 10, Path: "main.go", Body: "Possible nil dereference.", ThumbsDown: 1,
+			Replies: []*models.ThreadComment{{ID: 12, Author: "bob", Body: "Not an issue."}}}}, posted)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"code-reviewer-bot/internal/models"
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/google/go-github/v62/github"
 	"golang.org/x/oauth2"
@@ -12,6 +14,11 @@ import (
 // GitHubRepository implements the VcsRepository interface for GitHub.
 type GitHubRepository struct {
 	client *github.Client
+	// loginOnce looks up the authenticated account on first use; the login, or the
+	// error of a token that cannot read its own user, is kept for every later call.
+	loginOnce sync.Once
+	login     string
+	loginErr  error
 }
 
 // NewGitHubRepository creates a new client for interacting with the GitHub API.
@@ -61,3 +68,177 @@ func (g *GitHubRepository) PostGeneralComment(ctx context.Context, owner, repo s
 	_, _, err := g.client.Issues.CreateComment(ctx, owner, repo, prNumber, issueComment)
 	return err
 }
+
+func (g *GitHubRepository) GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*models.PRDetails, error) {
+	pr, _, err := g.client.PullRequests.Get(ctx, owner, repo, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request details: %w", err)
+	}
+	return &models.PRDetails{
+		Owner:      owner,
+		Repo:       repo,
+		PRNumber:   prNumber,
+		Title:      pr.GetTitle(),
+		Body:       pr.GetBody(),
+		Branch:     pr.GetHead().GetRef(),
+		BaseBranch: pr.GetBase().GetRef(),
+		URL:        pr.GetHTMLURL(),
+	}, nil
+}
+
+func (g *GitHubRepository) GetIssue(ctx context.Context, owner, repo string, number int) (*models.Issue, error) {
+	issue, _, err := g.client.Issues.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue #%d: %w", number, err)
+	}
+	return &models.Issue{Number: number, Title: issue.GetTitle(), Body: issue.GetBody()}, nil
+}
+
+func (g *GitHubRepository) FindCommentByMarker(ctx context.Context, owner, repo string, prNumber int, marker string) (int64, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := g.client.Issues.ListComments(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, c := range comments {
+			if strings.Contains(c.GetBody(), marker) && g.isOwnComment(ctx, c.GetUser()) {
+				return c.GetID(), nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return 0, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// AuthenticatedLogin returns the login of the token's account. Installation tokens
+// of GitHub Apps and Actions cannot read their own user and get an error. The lookup
+// is made once per client, whether it succeeds or not.
+func (g *GitHubRepository) AuthenticatedLogin(ctx context.Context) (string, error) {
+	g.loginOnce.Do(func() {
+		user, _, err := g.client.Users.Get(ctx, "")
+		if err != nil {
+			g.loginErr = fmt.Errorf("failed to get the authenticated user: %w", err)
+			return
+		}
+		g.login = user.GetLogin()
+	})
+	return g.login, g.loginErr
+}
+
+// isOwnComment reports whether the bot wrote a comment. When the token's account
+// cannot be looked up, as for an App, the bot is the only bot account that counts.
+func (g *GitHubRepository) isOwnComment(ctx context.Context, author *github.User) bool {
+	login, err := g.AuthenticatedLogin(ctx)
+	if err != nil {
+		return author.GetType() == "Bot"
+	}
+	return strings.EqualFold(author.GetLogin(), login)
+}
+
+// CanWrite reports whether user has write or admin permission on the repository.
+func (g *GitHubRepository) CanWrite(ctx context.Context, owner, repo, user string) (bool, error) {
+	level, _, err := g.client.Repositories.GetPermissionLevel(ctx, owner, repo, user)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the permission of %s: %w", user, err)
+	}
+	switch level.GetPermission() {
+	case "admin", "write":
+		return true, nil
+	}
+	return false, nil
+}
+
+func (g *GitHubRepository) UpdateGeneralComment(ctx context.Context, owner, repo string, commentID int64, body string) error {
+	_, _, err := g.client.Issues.EditComment(ctx, owner, repo, commentID, &github.IssueComment{Body: &body})
+	return err
+}
+
+func (g *GitHubRepository) ListThreadComments(ctx context.Context, owner, repo string, prNumber int, threadID int64) ([]*models.ThreadComment, error) {
+	var thread []*models.ThreadComment
+	if threadID == 0 {
+		opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			comments, resp, err := g.client.Issues.ListComments(ctx, owner, repo, prNumber, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list comments: %w", err)
+			}
+			for _, c := range comments {
+				thread = append(thread, &models.ThreadComment{ID: c.GetID(), Author: c.GetUser().GetLogin(), Body: c.GetBody()})
+			}
+			if resp.NextPage == 0 {
+				return thread, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+
+	opts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := g.client.PullRequests.ListComments(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list review comments: %w", err)
+		}
+		for _, c := range comments {
+			if c.GetID() == threadID || c.GetInReplyTo() == threadID {
+				thread = append(thread, &models.ThreadComment{ID: c.GetID(), Author: c.GetUser().GetLogin(), Body: c.GetBody()})
+			}
+		}
+		if resp.NextPage == 0 {
+			return thread, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (g *GitHubRepository) ReplyToThread(ctx context.Context, owner, repo string, prNumber int, threadID int64, body string) error {
+	if threadID == 0 {
+		return g.PostGeneralComment(ctx, owner, repo, prNumber, body)
+	}
+	_, _, err := g.client.PullRequests.CreateCommentInReplyTo(ctx, owner, repo, prNumber, body, threadID)
+	return err
+}
+
+func (g *GitHubRepository) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	file, _, _, err := g.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if file == nil {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+	return file.GetContent()
+}
+
+func (g *GitHubRepository) ListPostedComments(ctx context.Context, owner, repo string, prNumber int) ([]*models.PostedComment, error) {
+	var posted []*models.PostedComment
+	threads := make(map[int64]*models.PostedComment)
+	opts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := g.client.PullRequests.ListComments(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list review comments: %w", err)
+		}
+		for _, c := range comments {
+			if thread := threads[c.GetInReplyTo()]; thread != nil {
+				thread.Replies = append(thread.Replies, &models.ThreadComment{ID: c.GetID(), Author: c.GetUser().GetLogin(), Body: c.GetBody()})
+				continue
+			}
+			pc := &models.PostedComment{
+				ID:         c.GetID(),
+				Path:       c.GetPath(),
+				Body:       c.GetBody(),
+				ThumbsUp:   c.GetReactions().GetPlusOne(),
+				ThumbsDown: c.GetReactions().GetMinusOne(),
+			}
+			threads[pc.ID] = pc
+			posted = append(posted, pc)
+		}
+		if resp.NextPage == 0 {
+			return posted, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -127,3 +127,299 @@ func TestGitHubClient_PostGeneralComment(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestGitHubClient_GetPullRequest(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v3/repos/owner/repo/pulls/1", r.URL.Path)
+			resp := github.PullRequest{
+				Title:   github.String("Add retries"),
+				Body:    github.String("Fixes #3"),
+				HTMLURL: github.String("https://github.com/owner/repo/pull/1"),
+				Head:    &github.PullRequestBranch{Ref: github.String("feature")},
+				Base:    &github.PullRequestBranch{Ref: github.String("main")},
+			}
+			json.NewEncoder(w).Encode(resp)
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		pr, err := client.GetPullRequest(context.Background(), "owner", "repo", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.PRDetails{
+			Owner: "owner", Repo: "repo", PRNumber: 1,
+			Title: "Add retries", Body: "Fixes #3",
+			Branch: "feature", BaseBranch: "main",
+			URL: "https://github.com/owner/repo/pull/1",
+		}, pr)
+	})
+
+	t.Run("Failure - Not Found", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		_, err := client.GetPullRequest(context.Background(), "owner", "repo", 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestGitHubClient_GetIssue(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v3/repos/owner/repo/issues/3", r.URL.Path)
+			json.NewEncoder(w).Encode(github.Issue{Title: github.String("Requests time out"), Body: github.String("Retry on 503.")})
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		issue, err := client.GetIssue(context.Background(), "owner", "repo", 3)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.Issue{Number: 3, Title: "Requests time out", Body: "Retry on 503."}, issue)
+	})
+}
+
+func TestGitHubClient_FindCommentByMarker(t *testing.T) {
+	bot := &github.User{Login: github.String("review-bot"), Type: github.String("User")}
+	contributor := &github.User{Login: github.String("mallory"), Type: github.String("User")}
+
+	t.Run("Success - searches every page for the bot's comment", func(t *testing.T) {
+		var server *httptest.Server
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v3/user" {
+				json.NewEncoder(w).Encode(bot)
+				return
+			}
+			assert.Equal(t, "/api/v3/repos/owner/repo/issues/1/comments", r.URL.Path)
+			if r.URL.Query().Get("page") == "2" {
+				json.NewEncoder(w).Encode([]*github.IssueComment{{ID: github.Int64(42), Body: github.String("<!-- marker -->\nSummary"), User: bot}})
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/repos/owner/repo/issues/1/comments?page=2>; rel="next"`, server.URL))
+			json.NewEncoder(w).Encode([]*github.IssueComment{
+				{ID: github.Int64(7), Body: github.String("LGTM"), User: contributor},
+				{ID: github.Int64(8), Body: github.String("<!-- marker --> posted first"), User: contributor},
+			})
+		}
+		client, s := setupGitHubTestServer(t, handler)
+		server = s
+		defer server.Close()
+
+		id, err := client.FindCommentByMarker(context.Background(), "owner", "repo", 1, "<!-- marker -->")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), id)
+	})
+
+	t.Run("Success - app tokens only match bot accounts", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v3/user" {
+				http.Error(w, `{"message": "Resource not accessible by integration"}`, http.StatusForbidden)
+				return
+			}
+			json.NewEncoder(w).Encode([]*github.IssueComment{
+				{ID: github.Int64(8), Body: github.String("<!-- marker -->"), User: contributor},
+				{ID: github.Int64(9), Body: github.String("<!-- marker -->"), User: &github.User{Login: github.String("reviewer[bot]"), Type: github.String("Bot")}},
+			})
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		id, err := client.FindCommentByMarker(context.Background(), "owner", "repo", 1, "<!-- marker -->")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9), id)
+	})
+
+	t.Run("Success - no comment with the marker", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v3/user" {
+				json.NewEncoder(w).Encode(bot)
+				return
+			}
+			json.NewEncoder(w).Encode([]*github.IssueComment{{ID: github.Int64(7), Body: github.String("LGTM"), User: bot}})
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		id, err := client.FindCommentByMarker(context.Background(), "owner", "repo", 1, "<!-- marker -->")
+		assert.NoError(t, err)
+		assert.Zero(t, id)
+	})
+}
+
+func TestGitHubClient_UpdateGeneralComment(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			assert.Equal(t, "/api/v3/repos/owner/repo/issues/comments/42", r.URL.Path)
+			var comment github.IssueComment
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+			assert.Equal(t, "Updated", comment.GetBody())
+			json.NewEncoder(w).Encode(comment)
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		err := client.UpdateGeneralComment(context.Background(), "owner", "repo", 42, "Updated")
+		assert.NoError(t, err)
+	})
+}
+
+func TestGitHubClient_CanWrite(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/owner/repo/collaborators/alice/permission":
+			fmt.Fprint(w, `{"permission": "write"}`)
+		case "/api/v3/repos/owner/repo/collaborators/bob/permission":
+			fmt.Fprint(w, `{"permission": "read"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+	client, server := setupGitHubTestServer(t, handler)
+	defer server.Close()
+
+	t.Run("Success - collaborator with write permission", func(t *testing.T) {
+		ok, err := client.CanWrite(context.Background(), "owner", "repo", "alice")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Failure - read permission or unknown user", func(t *testing.T) {
+		ok, err := client.CanWrite(context.Background(), "owner", "repo", "bob")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		_, err = client.CanWrite(context.Background(), "owner", "repo", "mallory")
+		assert.Error(t, err)
+	})
+}
+
+func TestGitHubClient_AuthenticatedLogin(t *testing.T) {
+	t.Run("Success - looks the user up once", func(t *testing.T) {
+		calls := 0
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			assert.Equal(t, "/api/v3/user", r.URL.Path)
+			fmt.Fprint(w, `{"login": "ai-reviewer"}`)
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		for i := 0; i < 2; i++ {
+			login, err := client.AuthenticatedLogin(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "ai-reviewer", login)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Failure - an App token is not asked again", func(t *testing.T) {
+		calls := 0
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusForbidden)
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		for i := 0; i < 2; i++ {
+			_, err := client.AuthenticatedLogin(context.Background())
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestGitHubClient_ListThreadComments(t *testing.T) {
+	t.Run("Success - returns the comments of one review thread", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v3/repos/owner/repo/pulls/1/comments", r.URL.Path)
+			json.NewEncoder(w).Encode([]*github.PullRequestComment{
+				{ID: github.Int64(10), Body: github.String("Possible nil dereference."), User: &github.User{Login: github.String("ai-reviewer")}},
+				{ID: github.Int64(11), Body: github.String("Unrelated."), User: &github.User{Login: github.String("alice")}},
+				{ID: github.Int64(12), InReplyTo: github.Int64(10), Body: github.String("@ai-reviewer why?"), User: &github.User{Login: github.String("bob")}},
+			})
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		thread, err := client.ListThreadComments(context.Background(), "owner", "repo", 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.ThreadComment{
+			{ID: 10, Author: "ai-reviewer", Body: "Possible nil dereference."},
+			{ID: 12, Author: "bob", Body: "@ai-reviewer why?"},
+		}, thread)
+	})
+
+	t.Run("Success - returns the PR conversation without a thread", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v3/repos/owner/repo/issues/1/comments", r.URL.Path)
+			json.NewEncoder(w).Encode([]*github.IssueComment{{ID: github.Int64(7), Body: github.String("LGTM"), User: &github.User{Login: github.String("alice")}}})
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		thread, err := client.ListThreadComments(context.Background(), "owner", "repo", 1, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.ThreadComment{{ID: 7, Author: "alice", Body: "LGTM"}}, thread)
+	})
+}
+
+func TestGitHubClient_ReplyToThread(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/api/v3/repos/owner/repo/pulls/1/comments", r.URL.Path)
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"body": "Because x can be nil.", "in_reply_to": 10}`, string(body))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		err := client.ReplyToThread(context.Background(), "owner", "repo", 1, 10, "Because x can be nil.")
+		assert.NoError(t, err)
+	})
+}
+
+func TestGitHubClient_GetFileContent(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v3/repos/owner/repo/contents/main.go", r.URL.Path)
+			assert.Equal(t, "abc123", r.URL.Query().Get("ref"))
+			json.NewEncoder(w).Encode(github.RepositoryContent{Type: github.String("file"), Content: github.String("package main\n")})
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		content, err := client.GetFileContent(context.Background(), "owner", "repo", "main.go", "abc123")
+		assert.NoError(t, err)
+		assert.Equal(t, "package main\n", content)
+	})
+}
+
+func TestGitHubClient_ListPostedComments(t *testing.T) {
+	t.Run("Success - groups replies under their thread", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v3/repos/owner/repo/pulls/1/comments", r.URL.Path)
+			json.NewEncoder(w).Encode([]*github.PullRequestComment{
+				{ID: github.Int64(10), Path: github.String("main.go"), Body: github.String("Possible nil dereference."),
+					Reactions: &github.Reactions{PlusOne: github.Int(0), MinusOne: github.Int(2)}},
+				{ID: github.Int64(11), Path: github.String("util.go"), Body: github.String("Unused."), Reactions: &github.Reactions{PlusOne: github.Int(1)}},
+				{ID: github.Int64(12), InReplyTo: github.Int64(10), Body: github.String("Not an issue."), User: &github.User{Login: github.String("bob")}},
+			})
+		}
+		client, server := setupGitHubTestServer(t, handler)
+		defer server.Close()
+
+		posted, err := client.ListPostedComments(context.Background(), "owner", "repo", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.PostedComment{
+			{ID: 10, Path: "main.go", Body: "Possible nil dereference.", ThumbsDown: 2,
+				Replies: []*models.ThreadComment{{ID: 12, Author: "bob", Body: "Not an issue."}}},
+			{ID: 11, Path: "util.go", Body: "Unused.", ThumbsUp: 1},
+		}, posted)
+	})
+}
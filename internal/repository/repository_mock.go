@@ -41,6 +41,81 @@ func (m *MockVcsRepository) EXPECT() *MockVcsRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AuthenticatedLogin mocks base method.
+func (m *MockVcsRepository) AuthenticatedLogin(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticatedLogin", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticatedLogin indicates an expected call of AuthenticatedLogin.
+func (mr *MockVcsRepositoryMockRecorder) AuthenticatedLogin(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticatedLogin", reflect.TypeOf((*MockVcsRepository)(nil).AuthenticatedLogin), ctx)
+}
+
+// CanWrite mocks base method.
+func (m *MockVcsRepository) CanWrite(ctx context.Context, owner, repo, user string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanWrite", ctx, owner, repo, user)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CanWrite indicates an expected call of CanWrite.
+func (mr *MockVcsRepositoryMockRecorder) CanWrite(ctx, owner, repo, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanWrite", reflect.TypeOf((*MockVcsRepository)(nil).CanWrite), ctx, owner, repo, user)
+}
+
+// FindCommentByMarker mocks base method.
+func (m *MockVcsRepository) FindCommentByMarker(ctx context.Context, owner, repo string, prNumber int, marker string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCommentByMarker", ctx, owner, repo, prNumber, marker)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCommentByMarker indicates an expected call of FindCommentByMarker.
+func (mr *MockVcsRepositoryMockRecorder) FindCommentByMarker(ctx, owner, repo, prNumber, marker any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCommentByMarker", reflect.TypeOf((*MockVcsRepository)(nil).FindCommentByMarker), ctx, owner, repo, prNumber, marker)
+}
+
+// GetFileContent mocks base method.
+func (m *MockVcsRepository) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileContent", ctx, owner, repo, path, ref)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileContent indicates an expected call of GetFileContent.
+func (mr *MockVcsRepositoryMockRecorder) GetFileContent(ctx, owner, repo, path, ref any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileContent", reflect.TypeOf((*MockVcsRepository)(nil).GetFileContent), ctx, owner, repo, path, ref)
+}
+
+// GetIssue mocks base method.
+func (m *MockVcsRepository) GetIssue(ctx context.Context, owner, repo string, number int) (*models.Issue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIssue", ctx, owner, repo, number)
+	ret0, _ := ret[0].(*models.Issue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIssue indicates an expected call of GetIssue.
+func (mr *MockVcsRepositoryMockRecorder) GetIssue(ctx, owner, repo, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIssue", reflect.TypeOf((*MockVcsRepository)(nil).GetIssue), ctx, owner, repo, number)
+}
+
 // GetPRCommitID mocks base method.
 func (m *MockVcsRepository) GetPRCommitID(ctx context.Context, owner, repo string, prNumber int) (string, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +146,51 @@ func (mr *MockVcsRepositoryMockRecorder) GetPRDiff(ctx, owner, repo, prNumber an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPRDiff", reflect.TypeOf((*MockVcsRepository)(nil).GetPRDiff), ctx, owner, repo, prNumber)
 }
 
+// GetPullRequest mocks base method.
+func (m *MockVcsRepository) GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*models.PRDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPullRequest", ctx, owner, repo, prNumber)
+	ret0, _ := ret[0].(*models.PRDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPullRequest indicates an expected call of GetPullRequest.
+func (mr *MockVcsRepositoryMockRecorder) GetPullRequest(ctx, owner, repo, prNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequest", reflect.TypeOf((*MockVcsRepository)(nil).GetPullRequest), ctx, owner, repo, prNumber)
+}
+
+// ListPostedComments mocks base method.
+func (m *MockVcsRepository) ListPostedComments(ctx context.Context, owner, repo string, prNumber int) ([]*models.PostedComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPostedComments", ctx, owner, repo, prNumber)
+	ret0, _ := ret[0].([]*models.PostedComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPostedComments indicates an expected call of ListPostedComments.
+func (mr *MockVcsRepositoryMockRecorder) ListPostedComments(ctx, owner, repo, prNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostedComments", reflect.TypeOf((*MockVcsRepository)(nil).ListPostedComments), ctx, owner, repo, prNumber)
+}
+
+// ListThreadComments mocks base method.
+func (m *MockVcsRepository) ListThreadComments(ctx context.Context, owner, repo string, prNumber int, threadID int64) ([]*models.ThreadComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListThreadComments", ctx, owner, repo, prNumber, threadID)
+	ret0, _ := ret[0].([]*models.ThreadComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListThreadComments indicates an expected call of ListThreadComments.
+func (mr *MockVcsRepositoryMockRecorder) ListThreadComments(ctx, owner, repo, prNumber, threadID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListThreadComments", reflect.TypeOf((*MockVcsRepository)(nil).ListThreadComments), ctx, owner, repo, prNumber, threadID)
+}
+
 // PostGeneralComment mocks base method.
 func (m *MockVcsRepository) PostGeneralComment(ctx context.Context, owner, repo string, prNumber int, body string) error {
 	m.ctrl.T.Helper()
@@ -98,3 +218,31 @@ func (mr *MockVcsRepositoryMockRecorder) PostReview(ctx, owner, repo, prNumber,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostReview", reflect.TypeOf((*MockVcsRepository)(nil).PostReview), ctx, owner, repo, prNumber, comments, commitID)
 }
+
+// ReplyToThread mocks base method.
+func (m *MockVcsRepository) ReplyToThread(ctx context.Context, owner, repo string, prNumber int, threadID int64, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplyToThread", ctx, owner, repo, prNumber, threadID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplyToThread indicates an expected call of ReplyToThread.
+func (mr *MockVcsRepositoryMockRecorder) ReplyToThread(ctx, owner, repo, prNumber, threadID, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplyToThread", reflect.TypeOf((*MockVcsRepository)(nil).ReplyToThread), ctx, owner, repo, prNumber, threadID, body)
+}
+
+// UpdateGeneralComment mocks base method.
+func (m *MockVcsRepository) UpdateGeneralComment(ctx context.Context, owner, repo string, commentID int64, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGeneralComment", ctx, owner, repo, commentID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGeneralComment indicates an expected call of UpdateGeneralComment.
+func (mr *MockVcsRepositoryMockRecorder) UpdateGeneralComment(ctx, owner, repo, commentID, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGeneralComment", reflect.TypeOf((*MockVcsRepository)(nil).UpdateGeneralComment), ctx, owner, repo, commentID, body)
+}
@@ -1,15 +1,15 @@
 package service
 
 import (
+	"code-reviewer-bot/constants"
 	"code-reviewer-bot/internal/models"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
-
-	"github.com/firebase/genkit/go/ai"
 )
 
 var architectureLayers = map[string][]string{
@@ -19,7 +19,7 @@ var architectureLayers = map[string][]string{
 	"Configuration":   {"config", "env", "settings", "yaml", "configuration"},
 }
 
-func (s *ReviewService) reviewProjectArchitecture(ctx context.Context, repoPath string) (*models.ArchitectureReviewResponse, error) {
+func (s *ReviewService) reviewProjectArchitecture(ctx context.Context, run *reviewRun, repoPath string) (*models.ArchitectureReviewResponse, error) {
 	directories := getProjectDirectories(repoPath)
 	if len(directories) == 0 {
 		return &models.ArchitectureReviewResponse{
@@ -37,7 +37,7 @@ func (s *ReviewService) reviewProjectArchitecture(ctx context.Context, repoPath
 	score := calculateArchitectureScore(foundLayers)
 	summary := generateStructureSummary(directories, foundLayers)
 
-	comments, needsComment := generateArchitectureComments(ctx, s, summary, score, missingLayers)
+	comments, needsComment := generateArchitectureComments(ctx, s, run, summary, score, missingLayers)
 
 	return &models.ArchitectureReviewResponse{
 		Score:         score,
@@ -49,19 +49,29 @@ func (s *ReviewService) reviewProjectArchitecture(ctx context.Context, repoPath
 	}, nil
 }
 
-func generateArchitectureComments(ctx context.Context, s *ReviewService, summary string, score int, missingLayers []string) ([]models.Comment, bool) {
+// architectureInput is the input of the architecture prompt.
+type architectureInput struct {
+	Summary       string   `json:"summary"`
+	Score         int      `json:"score"`
+	MissingLayers []string `json:"missingLayers"`
+}
+
+func generateArchitectureComments(ctx context.Context, s *ReviewService, run *reviewRun, summary string, score int, missingLayers []string) ([]models.Comment, bool) {
 	// Only generate comment if there are issues
 	if score >= 8 && len(missingLayers) == 0 {
 		return []models.Comment{}, false
 	}
 
-	missingStr := "None"
-	if len(missingLayers) > 0 {
-		missingStr = strings.Join(missingLayers, ", ")
+	input := architectureInput{Summary: summary, Score: score, MissingLayers: missingLayers}
+	if input.MissingLayers == nil {
+		input.MissingLayers = []string{}
 	}
-
-	prompt := fmt.Sprintf(s.cfg.ArchitectureReviewPrompt, summary, score, missingStr)
-	response, err := genkitGenerate(ctx, s.g, ai.WithModelName(s.cfg.LLM.ModelName), ai.WithPrompt(prompt))
+	req, err := s.renderPrompt(ctx, run, constants.PROMPT_ARCHITECTURE, input)
+	if err != nil {
+		log.Printf("Failed to render architecture prompt: %v", err)
+		return generateFallbackArchitectureComments(score, missingLayers), true
+	}
+	response, _, err := s.generate(ctx, run, req)
 	if err != nil {
 		// Fallback comment if AI fails
 		return generateFallbackArchitectureComments(score, missingLayers), true
@@ -73,10 +83,13 @@ func generateArchitectureComments(ctx context.Context, s *ReviewService, summary
 
 	if err := json.Unmarshal([]byte(response.Text()), &aiResponse); err != nil {
 		return []models.Comment{{
-			Body: response.Text(),
+			Body: sanitizeModelText(response.Text()),
 		}}, true
 	}
 
+	for i := range aiResponse.Comments {
+		aiResponse.Comments[i].Body = sanitizeModelText(aiResponse.Comments[i].Body)
+	}
 	return aiResponse.Comments, true
 }
 
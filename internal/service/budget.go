@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code-reviewer-bot/internal/diffparser"
+)
+
+var errBudgetExhausted = errors.New("review budget exhausted")
+
+const (
+	prioritySource = iota
+	priorityTest
+	priorityDocs
+)
+
+var docExtensions = map[string]bool{
+	".md": true, ".rst": true, ".txt": true, ".adoc": true,
+}
+
+// checkBudget reports whether the run may make another LLM call.
+func (r *reviewRun) checkBudget(ctx context.Context) error {
+	budget := r.cfg.Review.Budget
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %v", errBudgetExhausted, err)
+	}
+	if budget.MaxTokens > 0 && r.usage.InputTokens+r.usage.OutputTokens >= budget.MaxTokens {
+		return fmt.Errorf("%w: token limit of %d reached", errBudgetExhausted, budget.MaxTokens)
+	}
+	if budget.MaxLLMCalls > 0 && r.llmCalls >= budget.MaxLLMCalls {
+		return fmt.Errorf("%w: limit of %d LLM calls reached", errBudgetExhausted, budget.MaxLLMCalls)
+	}
+	return nil
+}
+
+// filePriority ranks source files before tests, and tests before documentation.
+func filePriority(path string) int {
+	lower := strings.ToLower(path)
+	base := filepath.Base(lower)
+	if docExtensions[filepath.Ext(lower)] || strings.HasPrefix(lower, "docs/") || strings.Contains(lower, "/docs/") {
+		return priorityDocs
+	}
+	if strings.Contains(base, "_test.") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.Contains(lower, "__tests__/") ||
+		strings.HasPrefix(lower, "test/") || strings.HasPrefix(lower, "tests/") ||
+		strings.Contains(lower, "/test/") || strings.Contains(lower, "/tests/") {
+		return priorityTest
+	}
+	return prioritySource
+}
+
+// prioritizeChunks orders chunks so that the most valuable files are reviewed first
+// when the budget runs out. Chunks of the same priority keep their diff order.
+func prioritizeChunks(chunks []*diffparser.DiffChunk) {
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return filePriority(chunks[i].FilePath) < filePriority(chunks[j].FilePath)
+	})
+}
+
+// unreviewedFiles lists, in order and without duplicates, the files of the given
+// chunks. Files that also had a reviewed chunk are marked as partially reviewed.
+func unreviewedFiles(skipped []*diffparser.DiffChunk, reviewed map[string]bool) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, chunk := range skipped {
+		if seen[chunk.FilePath] {
+			continue
+		}
+		seen[chunk.FilePath] = true
+		if reviewed[chunk.FilePath] {
+			files = append(files, fmt.Sprintf("`%s` (partially reviewed)", chunk.FilePath))
+		} else {
+			files = append(files, fmt.Sprintf("`%s`", chunk.FilePath))
+		}
+	}
+	return files
+}
+
+func formatBudgetNote(reason error, files []string) string {
+	var b strings.Builder
+	b.WriteString("### ⚠️ Review Incomplete\n\n")
+	b.WriteString(fmt.Sprintf("The review stopped early (%v). The following files were not reviewed:\n\n", reason))
+	for _, f := range files {
+		b.WriteString(fmt.Sprintf("- %s\n", f))
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrioritizeChunks(t *testing.T) {
+	chunks := []*diffparser.DiffChunk{
+		{FilePath: "README.md"},
+		{FilePath: "internal/service/review_service_test.go"},
+		{FilePath: "internal/service/review_service.go"},
+		{FilePath: "web/__tests__/app.js"},
+		{FilePath: "docs/setup.txt"},
+		{FilePath: "cmd/server/main.go"},
+	}
+
+	prioritizeChunks(chunks)
+
+	var order []string
+	for _, c := range chunks {
+		order = append(order, c.FilePath)
+	}
+	assert.Equal(t, []string{
+		"internal/service/review_service.go",
+		"cmd/server/main.go",
+		"internal/service/review_service_test.go",
+		"web/__tests__/app.js",
+		"README.md",
+		"docs/setup.txt",
+	}, order)
+}
+
+func TestCheckBudget(t *testing.T) {
+	t.Run("Success - unlimited by default", func(t *testing.T) {
+		run := newReviewRun(&config.Config{})
+		run.llmCalls = 1000
+		run.usage.InputTokens = 1000000
+		assert.NoError(t, run.checkBudget(context.Background()))
+	})
+
+	t.Run("Failure - token limit reached", func(t *testing.T) {
+		run := newReviewRun(&config.Config{Review: config.ReviewConfig{Budget: config.BudgetConfig{MaxTokens: 100}}})
+		run.usage.InputTokens, run.usage.OutputTokens = 60, 40
+		err := run.checkBudget(context.Background())
+		assert.True(t, errors.Is(err, errBudgetExhausted))
+		assert.Contains(t, err.Error(), "token limit")
+	})
+
+	t.Run("Failure - deadline exceeded", func(t *testing.T) {
+		run := newReviewRun(&config.Config{})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		assert.True(t, errors.Is(run.checkBudget(ctx), errBudgetExhausted))
+	})
+}
+
+func TestGenerate_StopsWhenCallBudgetIsSpent(t *testing.T) {
+	cfg := &config.Config{
+		LLM:    config.LLMConfig{ModelName: "test-model"},
+		Review: config.ReviewConfig{Budget: config.BudgetConfig{MaxLLMCalls: 1}},
+	}
+	reviewService := NewReviewService(nil, nil, nil, cfg)
+
+	calls := 0
+	originalGenerate := genkitGenerate
+	genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		calls++
+		return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+	}
+	defer func() { genkitGenerate = originalGenerate }()
+
+	run := newReviewRun(cfg)
+	_, _, err := reviewService.generate(context.Background(), run, &ai.GenerateActionOptions{})
+	assert.NoError(t, err)
+	_, _, err = reviewService.generate(context.Background(), run, &ai.GenerateActionOptions{})
+	assert.True(t, errors.Is(err, errBudgetExhausted))
+	assert.Equal(t, 1, calls)
+}
+
+func TestUnreviewedFiles(t *testing.T) {
+	skipped := []*diffparser.DiffChunk{{FilePath: "a.go"}, {FilePath: "a.go"}, {FilePath: "b.go"}}
+	files := unreviewedFiles(skipped, map[string]bool{"a.go": true})
+	assert.Equal(t, []string{"`a.go` (partially reviewed)", "`b.go`"}, files)
+}
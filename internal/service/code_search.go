@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/codesearch"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+)
+
+// defaultSearchTopK is used when code_search.top_k is not set.
+const defaultSearchTopK = 3
+
+// relatedSnippet is code from elsewhere in the repository that resembles a hunk.
+type relatedSnippet struct {
+	File      string `json:"file"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Code      string `json:"code"`
+}
+
+// openCodeSearch loads and refreshes the keyword index of the clone when code search
+// is enabled. The index is cached per repository, outside the clone, which is removed
+// after the review. It returns nil when disabled or when the index cannot be built.
+func openCodeSearch(ctx context.Context, cfg config.CodeSearchConfig, prDetails *models.PRDetails, repoPath string) *codesearch.Index {
+	if !cfg.Enabled {
+		return nil
+	}
+	storePath, err := codeSearchStore(cfg.CacheDir, prDetails.Owner, prDetails.Repo)
+	if err != nil {
+		log.Printf("Warning: could not locate the code search index: %v", err)
+		return nil
+	}
+	idx, err := codesearch.Open(ctx, repoPath, storePath)
+	if err != nil {
+		log.Printf("Warning: could not build the code search index: %v", err)
+		return nil
+	}
+	return idx
+}
+
+// codeSearchStore returns where the index of owner/repo is kept.
+func codeSearchStore(cacheDir, owner, repo string) (string, error) {
+	if cacheDir == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			userCache = os.TempDir()
+		}
+		cacheDir = filepath.Join(userCache, "ai-review")
+	}
+	name := filepath.Join(owner, repo+".gob")
+	if owner == "" || repo == "" || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid repository %q/%q", owner, repo)
+	}
+	return filepath.Join(cacheDir, "code-search", name), nil
+}
+
+// relatedCode returns the snippets most similar to a hunk's added lines. The hunk's
+// own lines and files excluded by the path filters are never returned. Snippets that
+// would exceed the token budget are skipped.
+func relatedCode(idx *codesearch.Index, chunk *diffparser.DiffChunk, cfg config.CodeSearchConfig, paths config.PathFilterConfig) []relatedSnippet {
+	if idx == nil {
+		return nil
+	}
+	topK := cfg.TopK
+	if topK == 0 {
+		topK = defaultSearchTopK
+	}
+	hunkStart, hunkEnd := chunk.StartLineNew, chunk.StartLineNew+newLineCount(chunk.CodeSnippet)
+	skip := func(path string, start, end int) bool {
+		if path == chunk.FilePath && start <= hunkEnd && hunkStart <= end {
+			return true
+		}
+		return !paths.Allows(path)
+	}
+
+	var snippets []relatedSnippet
+	used := 0
+	for _, r := range idx.Search(addedLines(chunk.CodeSnippet), topK, skip) {
+		tokens := estimateTokens(r.Path) + estimateTokens(r.Code)
+		if cfg.MaxTokens > 0 && used+tokens > cfg.MaxTokens {
+			continue
+		}
+		used += tokens
+		snippets = append(snippets, relatedSnippet{File: r.Path, StartLine: r.StartLine, EndLine: r.EndLine, Code: r.Code})
+	}
+	return snippets
+}
+
+// newLineCount returns the number of lines a hunk spans in the new file.
+func newLineCount(snippet string) int {
+	n := 0
+	for _, line := range strings.Split(snippet, "\n") {
+		if !strings.HasPrefix(line, "-") {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/codesearch"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSearchIndex commits the files to a temporary git repository and indexes it.
+func newSearchIndex(t *testing.T, files map[string]string) *codesearch.Index {
+	t.Helper()
+	repoPath := t.TempDir()
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", repoPath}, args...)...).CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	git("init", "--quiet")
+	for name, content := range files {
+		path := filepath.Join(repoPath, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	git("add", "-A")
+	git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "init")
+
+	cfg := config.CodeSearchConfig{Enabled: true, CacheDir: t.TempDir()}
+	idx := openCodeSearch(context.Background(), cfg, &models.PRDetails{Owner: "owner", Repo: "repo"}, repoPath)
+	assert.NotNil(t, idx)
+	return idx
+}
+
+func TestRelatedCode(t *testing.T) {
+	idx := newSearchIndex(t, map[string]string{
+		"utils/comments.go":  "package utils\n\nfunc cleanCommentBody(body string) string {\n\treturn strings.TrimSpace(body)\n}\n",
+		"gen/comments.pb.go": "package gen\n\nfunc cleanCommentBody() {}\n",
+		"service/review.go":  "package service\n\nfunc post(body string) {\n\tbody = trimCommentBody(body)\n}\n",
+	})
+	chunk := &diffparser.DiffChunk{
+		FilePath:     "service/review.go",
+		StartLineNew: 3,
+		CodeSnippet:  " func post(body string) {\n+\tbody = trimCommentBody(body)\n }",
+	}
+	paths := config.PathFilterConfig{Exclude: []string{"**/*.pb.go"}}
+
+	t.Run("Success - returns similar code from other files", func(t *testing.T) {
+		snippets := relatedCode(idx, chunk, config.CodeSearchConfig{}, paths)
+		assert.Len(t, snippets, 1)
+		assert.Equal(t, "utils/comments.go", snippets[0].File)
+		assert.Equal(t, 1, snippets[0].StartLine)
+		assert.Contains(t, snippets[0].Code, "func cleanCommentBody(body string) string {")
+	})
+
+	t.Run("Success - without path filters every file may match", func(t *testing.T) {
+		snippets := relatedCode(idx, chunk, config.CodeSearchConfig{TopK: 5}, config.PathFilterConfig{})
+		var files []string
+		for _, s := range snippets {
+			files = append(files, s.File)
+		}
+		assert.ElementsMatch(t, []string{"utils/comments.go", "gen/comments.pb.go"}, files)
+	})
+
+	t.Run("Success - skips snippets beyond the budget", func(t *testing.T) {
+		assert.Empty(t, relatedCode(idx, chunk, config.CodeSearchConfig{MaxTokens: 10}, paths))
+	})
+
+	t.Run("Success - nothing without an index", func(t *testing.T) {
+		assert.Nil(t, relatedCode(nil, chunk, config.CodeSearchConfig{}, paths))
+	})
+}
+
+func TestAnalyzeChunk_RelatedCode(t *testing.T) {
+	cfg := &config.Config{LLM: config.LLMConfig{ModelName: "test-model"}, PromptDir: testPromptDir}
+	reviewService := NewReviewService(nil, nil, newTestGenkit(t), cfg)
+
+	var rendered string
+	originalGenerate := genkitGenerate
+	genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		rendered = req.Messages[len(req.Messages)-1].Text()
+		return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+	}
+	defer func() { genkitGenerate = originalGenerate }()
+
+	t.Run("Success - renders related snippets", func(t *testing.T) {
+		run := newReviewRun(cfg)
+		run.search = newSearchIndex(t, map[string]string{
+			"utils/comments.go": "package utils\n\nfunc cleanCommentBody(body string) string {\n\treturn body\n}\n",
+		})
+		_, _, err := reviewService.analyzeChunk(context.Background(), run, &diffparser.DiffChunk{FilePath: "main.go", CodeSnippet: "+\tbody = cleanCommentBody(body)"})
+		assert.NoError(t, err)
+		assert.Contains(t, rendered, "--- utils/comments.go (lines 1-6) ---\npackage utils\n")
+	})
+}
+
+func TestCodeSearchStore(t *testing.T) {
+	t.Run("Success - one index per repository in the cache", func(t *testing.T) {
+		path, err := codeSearchStore("/var/cache/bot", "owner", "repo")
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join("/var/cache/bot", "code-search", "owner", "repo.gob"), path)
+	})
+
+	t.Run("Failure - names escaping the cache", func(t *testing.T) {
+		_, err := codeSearchStore("/var/cache/bot", "..", "..")
+		assert.Error(t, err)
+		_, err = codeSearchStore("/var/cache/bot", "", "repo")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/storage"
+)
+
+// Slash commands understood in PR comments.
+const (
+	commandReview    = "review"
+	commandSummarize = "summarize"
+	commandIgnore    = "ignore"
+	commandPause     = "pause"
+	commandResume    = "resume"
+)
+
+// errCommandNotAllowed is returned for the commands of users who cannot push to the
+// repository; anyone else able to comment could otherwise spend the model budget or
+// change how the pull request is reviewed.
+var errCommandNotAllowed = errors.New("only users with write access can run commands")
+
+// Command is a slash command written on its own line of a PR comment, e.g.
+// "/ignore docs/**" has the name "ignore" and the argument "docs/**".
+type Command struct {
+	Name string
+	Arg  string
+}
+
+// ParseCommands returns the slash commands of a comment written by author, in the
+// order they appear. Lines in code blocks or quotes are not commands, nor is
+// anything the bot wrote itself.
+func (s *ReviewService) ParseCommands(ctx context.Context, author, body string) []Command {
+	if !s.cfg.Commands.Enabled || s.isBotAuthor(ctx, author) {
+		return nil
+	}
+	return parseCommands(body)
+}
+
+func parseCommands(body string) []Command {
+	var commands []Command
+	inCode := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || !strings.HasPrefix(line, "/") {
+			continue
+		}
+		name, arg, _ := strings.Cut(line[1:], " ")
+		switch name = strings.ToLower(name); name {
+		case commandReview, commandSummarize, commandIgnore, commandPause, commandResume:
+			commands = append(commands, Command{Name: name, Arg: strings.TrimSpace(arg)})
+		}
+	}
+	return commands
+}
+
+// RunCommands carries out the commands of a PR comment in order and answers each in
+// the comment's thread. A failed command is reported there and does not stop the
+// ones after it. Only users with write access to the repository can run commands,
+// and the bot's own comments are ignored.
+func (s *ReviewService) RunCommands(baseUrl string, ctx context.Context, comment *models.Mention, commands []Command) error {
+	if s.isBotAuthor(ctx, comment.Author) {
+		return nil
+	}
+	allowed, err := s.repo.CanWrite(ctx, comment.Owner, comment.Repo, comment.Author)
+	if err != nil {
+		return fmt.Errorf("failed to check the permission of @%s: %w", comment.Author, err)
+	}
+	if !allowed {
+		return fmt.Errorf("@%s: %w", comment.Author, errCommandNotAllowed)
+	}
+	var failed int
+	for _, cmd := range commands {
+		log.Printf("Running /%s from @%s on PR #%d in %s/%s", cmd.Name, comment.Author, comment.PRNumber, comment.Owner, comment.Repo)
+		reply, err := s.runCommand(baseUrl, ctx, comment, cmd)
+		if err != nil {
+			log.Printf("Command /%s failed: %v", cmd.Name, err)
+			failed++
+			reply = fmt.Sprintf("❌ `/%s` failed: %v", cmd.Name, err)
+		}
+		if reply == "" {
+			continue
+		}
+		if err := s.repo.ReplyToThread(ctx, comment.Owner, comment.Repo, comment.PRNumber, comment.ThreadID, reply); err != nil {
+			return fmt.Errorf("failed to answer /%s: %w", cmd.Name, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commands failed", failed, len(commands))
+	}
+	return nil
+}
+
+// runCommand carries out one command and returns the reply to post, if any.
+func (s *ReviewService) runCommand(baseUrl string, ctx context.Context, comment *models.Mention, cmd Command) (string, error) {
+	switch cmd.Name {
+	case commandReview:
+		// Every review covers the whole diff of the pull request, so "/review full"
+		// does the same as "/review".
+		if cmd.Arg != "" && cmd.Arg != "full" {
+			return "", fmt.Errorf("unknown option %q, use `/review` or `/review full`", cmd.Arg)
+		}
+		prDetails, err := s.repo.GetPullRequest(ctx, comment.Owner, comment.Repo, comment.PRNumber)
+		if err != nil {
+			return "", err
+		}
+		// The review reports its outcome in the summary comment.
+		_, err = s.processPullRequest(baseUrl, ctx, prDetails, true)
+		return "", err
+	case commandSummarize:
+		return s.summarizeOnDemand(ctx, comment)
+	case commandIgnore:
+		if cmd.Arg == "" || strings.ContainsAny(cmd.Arg, " \t") {
+			return "", fmt.Errorf("expected a single path glob, e.g. `/ignore docs/**`")
+		}
+		err := s.updatePRState(ctx, comment, func(state *models.PRState) {
+			if !slices.Contains(state.IgnoredPaths, cmd.Arg) {
+				state.IgnoredPaths = append(state.IgnoredPaths, cmd.Arg)
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("🙈 Files matching `%s` are left out of reviews of this pull request.", cmd.Arg), nil
+	case commandPause, commandResume:
+		paused := cmd.Name == commandPause
+		if err := s.updatePRState(ctx, comment, func(state *models.PRState) { state.Paused = paused }); err != nil {
+			return "", err
+		}
+		if paused {
+			return "⏸️ Automatic reviews of this pull request are paused. Comment `/resume` to restart them or `/review` to ask for one.", nil
+		}
+		return "▶️ Automatic reviews of this pull request are back on.", nil
+	}
+	return "", fmt.Errorf("unknown command")
+}
+
+// summarizeOnDemand answers /summarize with a summary of the current diff, leaving
+// out the files the path filters exclude.
+func (s *ReviewService) summarizeOnDemand(ctx context.Context, comment *models.Mention) (string, error) {
+	prDetails := &models.PRDetails{Owner: comment.Owner, Repo: comment.Repo, PRNumber: comment.PRNumber}
+	run := newReviewRun(s.cfg)
+	run.cfg = withIgnoredPaths(s.cfg, s.loadPRState(ctx, prDetails).IgnoredPaths)
+
+	diff, err := s.repo.GetPRDiff(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR diff: %w", err)
+	}
+	chunks := diffparser.Parse(filterDiff(diff, run.cfg.Review.Paths))
+	if len(chunks) == 0 {
+		return "There are no reviewable changes to summarize.", nil
+	}
+	run.intent = s.loadPRIntent(ctx, prDetails)
+	return s.summarizePullRequest(ctx, run, chunks, nil)
+}
+
+// loadPRState returns the slash-command state of a pull request. Without a store,
+// or when it cannot be read, the pull request is treated as having none.
+func (s *ReviewService) loadPRState(ctx context.Context, prDetails *models.PRDetails) *models.PRState {
+	if s.store == nil {
+		return &models.PRState{}
+	}
+	state, err := s.store.GetPRState(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber)
+	if err != nil {
+		log.Printf("Warning: could not load the state of PR #%d: %v", prDetails.PRNumber, err)
+		return &models.PRState{}
+	}
+	return state
+}
+
+// updatePRState applies change to the stored state of the comment's pull request.
+func (s *ReviewService) updatePRState(ctx context.Context, comment *models.Mention, change func(*models.PRState)) error {
+	if s.store == nil {
+		return storage.ErrNoDatabase
+	}
+	state, err := s.store.GetPRState(ctx, comment.Owner, comment.Repo, comment.PRNumber)
+	if err != nil {
+		return err
+	}
+	change(state)
+	return s.store.SavePRState(ctx, comment.Owner, comment.Repo, comment.PRNumber, state)
+}
+
+// withIgnoredPaths adds the globs of /ignore commands to the configured excludes.
+func withIgnoredPaths(cfg *config.Config, ignored []string) *config.Config {
+	if len(ignored) == 0 {
+		return cfg
+	}
+	return cfg.WithRepoConfig(&config.RepoConfig{Paths: config.PathFilterConfig{Exclude: ignored}})
+}
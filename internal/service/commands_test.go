@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/repository"
+	"code-reviewer-bot/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseCommands(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockRepo := repository.NewMockVcsRepository(ctrl)
+	mockRepo.EXPECT().AuthenticatedLogin(gomock.Any()).Return("ai-reviewer[bot]", nil).AnyTimes()
+	s := NewReviewService(mockRepo, nil, nil, &config.Config{
+		Commands:     config.CommandsConfig{Enabled: true},
+		Conversation: config.ConversationConfig{BotName: "ai-reviewer"},
+	})
+
+	t.Run("Success - one command per line", func(t *testing.T) {
+		body := "Thanks!\n/ignore docs/**\n  /Review full\n/approve\n> /pause\n```\n/resume\n```\n/summarize"
+		assert.Equal(t, []Command{
+			{Name: "ignore", Arg: "docs/**"},
+			{Name: "review", Arg: "full"},
+			{Name: "summarize"},
+		}, s.ParseCommands(ctx, "bob", body))
+	})
+
+	t.Run("Success - none in the bot's own comments", func(t *testing.T) {
+		assert.Empty(t, s.ParseCommands(ctx, "ai-reviewer", "/review"))
+		assert.Empty(t, s.ParseCommands(ctx, "AI-Reviewer[bot]", "/review"))
+	})
+
+	t.Run("Success - none while commands are disabled", func(t *testing.T) {
+		disabled := NewReviewService(nil, nil, nil, &config.Config{})
+		assert.Empty(t, disabled.ParseCommands(ctx, "bob", "/review"))
+	})
+}
+
+func TestRunCommands(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Commands: config.CommandsConfig{Enabled: true}}
+	comment := &models.Mention{Owner: "test", Repo: "repo", PRNumber: 1, CommentID: 5, Author: "bob", ThreadID: 3}
+
+	t.Run("Success - stores /ignore and /pause", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockStore := storage.NewMockReviewStore(ctrl)
+		mockRepo.EXPECT().AuthenticatedLogin(gomock.Any()).Return("ai-reviewer", nil)
+		mockRepo.EXPECT().CanWrite(gomock.Any(), "test", "repo", "bob").Return(true, nil)
+		state := &models.PRState{PRNumber: 1, IgnoredPaths: []string{"vendor/**"}}
+		mockStore.EXPECT().GetPRState(gomock.Any(), "test", "repo", 1).Return(state, nil).Times(2)
+		mockStore.EXPECT().SavePRState(gomock.Any(), "test", "repo", 1, state).Return(nil).Times(2)
+		mockRepo.EXPECT().ReplyToThread(gomock.Any(), "test", "repo", 1, int64(3), "🙈 Files matching `docs/**` are left out of reviews of this pull request.")
+		mockRepo.EXPECT().ReplyToThread(gomock.Any(), "test", "repo", 1, int64(3), gomock.Any())
+
+		err := NewReviewService(mockRepo, mockStore, nil, cfg).RunCommands("", ctx, comment, []Command{{Name: "ignore", Arg: "docs/**"}, {Name: "pause"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"vendor/**", "docs/**"}, state.IgnoredPaths)
+		assert.True(t, state.Paused)
+	})
+
+	t.Run("Failure - reports commands that cannot run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().AuthenticatedLogin(gomock.Any()).Return("ai-reviewer", nil)
+		mockRepo.EXPECT().CanWrite(gomock.Any(), "test", "repo", "bob").Return(true, nil)
+		mockRepo.EXPECT().ReplyToThread(gomock.Any(), "test", "repo", 1, int64(3), "❌ `/pause` failed: no database is configured")
+		mockRepo.EXPECT().ReplyToThread(gomock.Any(), "test", "repo", 1, int64(3), "❌ `/review` failed: unknown option \"later\", use `/review` or `/review full`")
+		mockRepo.EXPECT().ReplyToThread(gomock.Any(), "test", "repo", 1, int64(3), "❌ `/ignore` failed: expected a single path glob, e.g. `/ignore docs/**`")
+
+		err := NewReviewService(mockRepo, nil, nil, cfg).RunCommands("", ctx, comment, []Command{{Name: "pause"}, {Name: "review", Arg: "later"}, {Name: "ignore"}})
+		assert.EqualError(t, err, "3 of 3 commands failed")
+	})
+
+	t.Run("Failure - users without write access cannot run commands", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().AuthenticatedLogin(gomock.Any()).Return("ai-reviewer", nil)
+		mockRepo.EXPECT().CanWrite(gomock.Any(), "test", "repo", "bob").Return(false, nil)
+
+		err := NewReviewService(mockRepo, nil, nil, cfg).RunCommands("", ctx, comment, []Command{{Name: "pause"}})
+		assert.ErrorIs(t, err, errCommandNotAllowed)
+	})
+
+	t.Run("Success - the bot's own comments are ignored without a bot_name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().AuthenticatedLogin(gomock.Any()).Return("bob", nil)
+
+		err := NewReviewService(mockRepo, nil, nil, cfg).RunCommands("", ctx, comment, []Command{{Name: "pause"}})
+		assert.NoError(t, err)
+	})
+}
+
+func TestProcessPullRequest_Paused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := repository.NewMockVcsRepository(ctrl)
+	mockStore := storage.NewMockReviewStore(ctrl)
+	mockStore.EXPECT().GetPRState(gomock.Any(), "test", "repo", 1).Return(&models.PRState{Paused: true}, nil)
+
+	// No VCS call is expected: a paused pull request is not even cloned.
+	result, err := NewReviewService(mockRepo, mockStore, nil, &config.Config{}).
+		ProcessPullRequest("", context.Background(), &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "Automatic reviews are paused.", result)
+}
+
+func TestWithIgnoredPaths(t *testing.T) {
+	cfg := &config.Config{Review: config.ReviewConfig{Paths: config.PathFilterConfig{Exclude: []string{"*.md"}}}}
+
+	assert.Same(t, cfg, withIgnoredPaths(cfg, nil))
+	merged := withIgnoredPaths(cfg, []string{"docs/**"})
+	assert.Equal(t, []string{"*.md", "docs/**"}, merged.Review.Paths.Exclude)
+	assert.Equal(t, []string{"*.md"}, cfg.Review.Paths.Exclude)
+}
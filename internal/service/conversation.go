@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+)
+
+const (
+	// defaultMaxHistory and defaultContextLines are used when the conversation
+	// settings leave them at 0.
+	defaultMaxHistory   = 10
+	defaultContextLines = 20
+	// maxHistoryBytes caps each earlier comment of a thread in the reply prompt.
+	maxHistoryBytes = 2000
+)
+
+// replyInput is the input of the reply prompt.
+type replyInput struct {
+	Boundary    string            `json:"boundary"`
+	BotName     string            `json:"botName"`
+	Author      string            `json:"author"`
+	Question    string            `json:"question"`
+	Language    string            `json:"language,omitempty"`
+	PullRequest *pullRequestInput `json:"pullRequest,omitempty"`
+	History     []historyInput    `json:"history,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	Line        int               `json:"line,omitempty"`
+	DiffHunk    string            `json:"diffHunk,omitempty"`
+	FileContext string            `json:"fileContext,omitempty"`
+}
+
+type historyInput struct {
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
+// errReplyNotAllowed is returned for mentions by users who cannot push to the
+// repository, so that drive-by comments cannot spend the model budget.
+var errReplyNotAllowed = errors.New("only users with write access get replies")
+
+// mentionPattern matches an @mention and captures the mentioned login; mail
+// addresses and paths such as "ops@host" are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@/.-])@([\w-]+(?:\.[\w-]+)*(?:\[bot\])?)`)
+
+// MentionsBot reports whether a comment written by author addresses the bot. The
+// bot's own comments never do, so its replies cannot trigger further replies.
+func (s *ReviewService) MentionsBot(ctx context.Context, author, body string) bool {
+	if !s.cfg.Conversation.Enabled || s.isBotAuthor(ctx, author) {
+		return false
+	}
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		if strings.EqualFold(strings.TrimSuffix(m[1], "[bot]"), s.cfg.Conversation.BotName) {
+			return true
+		}
+	}
+	return false
+}
+
+// botLogin returns the bot's login: bot_name or, when it is not set, the account of
+// the VCS token without the "[bot]" suffix of GitHub Apps. It returns "" when
+// neither is known.
+func (s *ReviewService) botLogin(ctx context.Context) string {
+	if name := s.cfg.Conversation.BotName; name != "" {
+		return name
+	}
+	login, err := s.repo.AuthenticatedLogin(ctx)
+	if err != nil {
+		log.Printf("Warning: the bot's account cannot be looked up, set conversation.bot_name: %v", err)
+		return ""
+	}
+	return strings.TrimSuffix(login, "[bot]")
+}
+
+// isBotAuthor reports whether author is the bot itself: the configured bot_name or
+// the account of the VCS token. GitHub Apps post as "<name>[bot]" and their tokens
+// cannot look up their own account, so the suffix is ignored when comparing.
+func (s *ReviewService) isBotAuthor(ctx context.Context, author string) bool {
+	name := strings.TrimSuffix(author, "[bot]")
+	if botName := s.cfg.Conversation.BotName; botName != "" && strings.EqualFold(name, botName) {
+		return true
+	}
+	login, err := s.repo.AuthenticatedLogin(ctx)
+	return err == nil && strings.EqualFold(name, strings.TrimSuffix(login, "[bot]"))
+}
+
+// ReplyToMention answers a comment that mentions the bot, in the thread it was
+// written in. The model sees the thread so far, the PR's stated intent and, for
+// comments on a diff line, the hunk and the surrounding lines of the file. Only
+// users with write access get an answer, and only within the review budget.
+func (s *ReviewService) ReplyToMention(ctx context.Context, mention *models.Mention) error {
+	allowed, err := s.repo.CanWrite(ctx, mention.Owner, mention.Repo, mention.Author)
+	if err != nil {
+		return fmt.Errorf("failed to check the permission of @%s: %w", mention.Author, err)
+	}
+	if !allowed {
+		return fmt.Errorf("@%s: %w", mention.Author, errReplyNotAllowed)
+	}
+	// generateCtx bounds the model call by the wall-clock budget of a review; the
+	// reply is posted with the parent context.
+	generateCtx := ctx
+	if d := s.cfg.Review.Budget.MaxDuration; d > 0 {
+		var cancel context.CancelFunc
+		generateCtx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	run := newReviewRun(s.cfg)
+	if err := run.checkBudget(generateCtx); err != nil {
+		return err
+	}
+	log.Printf("Answering @%s on PR #%d in %s/%s", mention.Author, mention.PRNumber, mention.Owner, mention.Repo)
+	c := s.cfg.Conversation
+	prDetails := &models.PRDetails{Owner: mention.Owner, Repo: mention.Repo, PRNumber: mention.PRNumber}
+	boundary, err := newBoundary()
+	if err != nil {
+		return err
+	}
+
+	input := replyInput{
+		Boundary:    boundary,
+		BotName:     c.BotName,
+		Author:      mention.Author,
+		Question:    truncateText(mention.Body, maxIntentBytes),
+		Language:    s.cfg.Review.Language,
+		PullRequest: s.loadPRIntent(ctx, prDetails),
+		History:     s.threadHistory(ctx, mention),
+		Path:        mention.Path,
+		Line:        mention.Line,
+		DiffHunk:    mention.DiffHunk,
+	}
+	if mention.Path != "" && mention.Line > 0 && mention.CommitID != "" {
+		content, err := s.repo.GetFileContent(ctx, mention.Owner, mention.Repo, mention.Path, mention.CommitID)
+		if err != nil {
+			log.Printf("Warning: could not read %s for context: %v", mention.Path, err)
+		} else {
+			contextLines := c.ContextLines
+			if contextLines == 0 {
+				contextLines = defaultContextLines
+			}
+			input.FileContext = fileExcerpt(content, mention.Line, contextLines)
+		}
+	}
+
+	req, err := s.renderPrompt(ctx, run, constants.PROMPT_REPLY, input)
+	if err != nil {
+		return err
+	}
+	res, _, err := s.generate(generateCtx, run, req)
+	if err != nil {
+		return fmt.Errorf("failed to generate reply: %w", err)
+	}
+	reply := sanitizeModelText(strings.TrimSpace(res.Text()))
+	if err := s.repo.ReplyToThread(ctx, mention.Owner, mention.Repo, mention.PRNumber, mention.ThreadID, reply); err != nil {
+		return fmt.Errorf("failed to post reply: %w", err)
+	}
+	log.Printf("Replied to @%s. %s.", mention.Author, formatUsage(run.usage))
+	return nil
+}
+
+// threadHistory returns the comments of the mention's thread that precede it, at
+// most MaxHistory of them. A thread that cannot be read is treated as empty.
+func (s *ReviewService) threadHistory(ctx context.Context, mention *models.Mention) []historyInput {
+	comments, err := s.repo.ListThreadComments(ctx, mention.Owner, mention.Repo, mention.PRNumber, mention.ThreadID)
+	if err != nil {
+		log.Printf("Warning: could not read the comment thread: %v", err)
+		return nil
+	}
+	var history []historyInput
+	for _, comment := range comments {
+		if comment.ID == mention.CommentID {
+			break
+		}
+		history = append(history, historyInput{Author: comment.Author, Body: truncateText(comment.Body, maxHistoryBytes)})
+	}
+	maxHistory := s.cfg.Conversation.MaxHistory
+	if maxHistory == 0 {
+		maxHistory = defaultMaxHistory
+	}
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return history
+}
+
+// fileExcerpt returns the lines within radius of line, numbered, with the line
+// itself marked by ">".
+func fileExcerpt(content string, line, radius int) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	start, end := max(1, line-radius), min(len(lines), line+radius)
+	width := len(fmt.Sprint(end))
+	var b strings.Builder
+	for n := start; n <= end; n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %*d | %s\n", marker, width, n, lines[n-1])
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+)
+
+const (
+	// defaultMinRejections and defaultSimilarity are used when the feedback settings
+	// leave them at 0.
+	defaultMinRejections = 2
+	defaultSimilarity    = 0.6
+	// maxRejectedComments caps the rejected comments suppression patterns are built from.
+	maxRejectedComments = 500
+	// maxFeedbackReason caps the stored text of a dismissing reply.
+	maxFeedbackReason = 500
+)
+
+// dismissalPattern matches replies in which a developer rejects a review comment.
+// The phrase must open the reply, optionally after "this is" or the like, so that
+// replies accepting a comment ("good catch, not what I intended") do not match.
+var dismissalPattern = regexp.MustCompile(`(?i)^\W*(?:(?:this|that|it)(?:'s|\s+is|\s+was)?\s+)?(?:not an? (?:real )?(?:issue|problem|bug)|false positive|won'?t fix|wontfix|by design|not applicable)\b`)
+
+// suppression is a kind of finding developers of a repository rejected repeatedly.
+type suppression struct {
+	category string
+	// dir is the deepest directory holding all the rejected comments; "" is the
+	// whole repository.
+	dir   string
+	words map[string]bool
+	// rejections is the number of rejected comments behind the pattern.
+	rejections int
+}
+
+// CollectFeedback reads the reactions and replies on a pull request's line comments
+// and stores the verdicts on the bot's comments: a 👎 or a dismissing reply rejects
+// a comment, a 👍 accepts it.
+func (s *ReviewService) CollectFeedback(ctx context.Context, prDetails *models.PRDetails) error {
+	if s.store == nil || !s.cfg.Review.Feedback.Enabled {
+		return nil
+	}
+	posted, err := s.repo.ListPostedComments(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to list review comments: %w", err)
+	}
+	feedback := feedbackFor(posted, s.botLogin(ctx))
+	if len(feedback) == 0 {
+		return nil
+	}
+	log.Printf("Recording feedback on %d comments of PR #%d", len(feedback), prDetails.PRNumber)
+	return s.store.RecordFeedback(ctx, prDetails.Owner, prDetails.Repo, feedback)
+}
+
+// feedbackFor turns reactions and replies into verdicts. Replies written by the bot
+// itself are not feedback.
+func feedbackFor(posted []*models.PostedComment, botName string) []*models.CommentFeedback {
+	var feedback []*models.CommentFeedback
+	for _, c := range posted {
+		f := &models.CommentFeedback{Path: c.Path, Body: c.Body}
+		if c.ThumbsDown > c.ThumbsUp {
+			f.Verdict, f.Reason = constants.FEEDBACK_REJECTED, "👎"
+		} else if reply := dismissingReply(c.Replies, botName); reply != nil {
+			f.Verdict, f.Reason = constants.FEEDBACK_REJECTED, truncateText(reply.Body, maxFeedbackReason)
+		} else if c.ThumbsUp > 0 {
+			f.Verdict, f.Reason = constants.FEEDBACK_ACCEPTED, "👍"
+		} else {
+			continue
+		}
+		feedback = append(feedback, f)
+	}
+	return feedback
+}
+
+func dismissingReply(replies []*models.ThreadComment, botName string) *models.ThreadComment {
+	for _, r := range replies {
+		if !strings.EqualFold(strings.TrimSuffix(r.Author, "[bot]"), botName) && dismissalPattern.MatchString(r.Body) {
+			return r
+		}
+	}
+	return nil
+}
+
+// loadSuppressions records the feedback given on the pull request so far and builds
+// the repository's suppression patterns from every rejected comment.
+func (s *ReviewService) loadSuppressions(ctx context.Context, prDetails *models.PRDetails, cfg config.FeedbackConfig) []*suppression {
+	if s.store == nil || !cfg.Enabled {
+		return nil
+	}
+	if err := s.CollectFeedback(ctx, prDetails); err != nil {
+		log.Printf("Warning: could not collect feedback: %v", err)
+	}
+	rejected, err := s.store.ListRejectedComments(ctx, prDetails.Owner, prDetails.Repo, maxRejectedComments)
+	if err != nil {
+		log.Printf("Warning: could not load rejected comments: %v", err)
+		return nil
+	}
+	suppressions := buildSuppressions(rejected, cfg)
+	if len(suppressions) > 0 {
+		log.Printf("Loaded %d suppression patterns from %d rejected comments", len(suppressions), len(rejected))
+	}
+	return suppressions
+}
+
+// buildSuppressions groups rejected comments of the same category with similar
+// messages. Groups of at least MinRejections comments become patterns.
+func buildSuppressions(rejected []*models.PRComment, cfg config.FeedbackConfig) []*suppression {
+	minRejections, similarity := feedbackThresholds(cfg)
+	var groups []*suppression
+	for _, c := range rejected {
+		words := messageWords(commentMessage(c.CommentText))
+		if len(words) == 0 {
+			continue
+		}
+		var group *suppression
+		for _, g := range groups {
+			if g.category == c.CommentType && wordSimilarity(g.words, words) >= similarity {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			groups = append(groups, &suppression{category: c.CommentType, dir: path.Dir(c.FilePath), words: words, rejections: 1})
+			continue
+		}
+		group.dir = commonDir(group.dir, path.Dir(c.FilePath))
+		group.rejections++
+	}
+
+	var suppressions []*suppression
+	for _, g := range groups {
+		if g.rejections >= minRejections {
+			if g.dir == "." {
+				g.dir = ""
+			}
+			suppressions = append(suppressions, g)
+		}
+	}
+	return suppressions
+}
+
+// matchSuppression returns the pattern that suppresses a finding in filePath, if any.
+func matchSuppression(suppressions []*suppression, filePath string, finding models.ReviewComment, cfg config.FeedbackConfig) *suppression {
+	if len(suppressions) == 0 {
+		return nil
+	}
+	_, similarity := feedbackThresholds(cfg)
+	words := messageWords(finding.Message)
+	for _, p := range suppressions {
+		if p.category != finding.Category || (p.dir != "" && !strings.HasPrefix(filePath, p.dir+"/")) {
+			continue
+		}
+		if wordSimilarity(p.words, words) >= similarity {
+			return p
+		}
+	}
+	return nil
+}
+
+// logAcceptance logs, per category, the share of the repository's comments nobody rejected.
+func (s *ReviewService) logAcceptance(ctx context.Context, prDetails *models.PRDetails, cfg config.FeedbackConfig) {
+	if s.store == nil || !cfg.Enabled {
+		return
+	}
+	stats, err := s.store.FeedbackStats(ctx, prDetails.Owner, prDetails.Repo)
+	if err != nil {
+		log.Printf("Warning: could not load feedback stats: %v", err)
+		return
+	}
+	for _, st := range stats {
+		if st.Posted == 0 {
+			continue
+		}
+		category := st.Category
+		if category == "" {
+			category = "uncategorized"
+		}
+		accepted := st.Posted - st.Rejected
+		log.Printf("Acceptance rate for %s in %s/%s: %.0f%% (%d of %d comments not rejected)",
+			category, prDetails.Owner, prDetails.Repo, 100*float64(accepted)/float64(st.Posted), accepted, st.Posted)
+	}
+}
+
+func feedbackThresholds(cfg config.FeedbackConfig) (int, float64) {
+	minRejections, similarity := cfg.MinRejections, cfg.Similarity
+	if minRejections == 0 {
+		minRejections = defaultMinRejections
+	}
+	if similarity == 0 {
+		similarity = defaultSimilarity
+	}
+	return minRejections, similarity
+}
+
+// commentMessage strips the severity header and the suggested change from a
+// posted comment body, leaving the finding's message.
+func commentMessage(body string) string {
+	if _, rest, found := strings.Cut(body, "\n\n"); found {
+		body = rest
+	}
+	for _, marker := range []string{"\n\n```suggestion", "\n\n**Suggested change:**"} {
+		body, _, _ = strings.Cut(body, marker)
+	}
+	return body
+}
+
+// messageWords returns the distinct lower-cased words of a message, ignoring words
+// shorter than three letters.
+func messageWords(message string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		if len(w) >= 3 {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// wordSimilarity is the Jaccard index of two word sets.
+func wordSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// commonDir returns the deepest directory containing both directories, "." when
+// they share none.
+func commonDir(a, b string) string {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] && as[n] != "." {
+		n++
+	}
+	if n == 0 {
+		return "."
+	}
+	return strings.Join(as[:n], "/")
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/repository"
+	"code-reviewer-bot/internal/storage"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFeedbackFor(t *testing.T) {
+	posted := []*models.PostedComment{
+		{Path: "a.go", Body: "down", ThumbsDown: 2, ThumbsUp: 1},
+		{Path: "b.go", Body: "dismissed", ThumbsUp: 1, Replies: []*models.ThreadComment{
+			{Author: "ai-reviewer", Body: "Sorry, this is not an issue."},
+			{Author: "bob", Body: "False positive: the map is never nil."},
+		}},
+		{Path: "c.go", Body: "up", ThumbsUp: 1},
+		{Path: "d.go", Body: "argued", Replies: []*models.ThreadComment{{Author: "ai-reviewer", Body: "This is intended."}}},
+		{Path: "e.go", Body: "accepted", Replies: []*models.ThreadComment{
+			{Author: "bob", Body: "Good catch, not what I intended, fixed."},
+			{Author: "carol", Body: "Fixed; this was not an issue before the refactor."},
+		}},
+		{Path: "f.go", Body: "won't fix", Replies: []*models.ThreadComment{{Author: "bob", Body: "That's by design."}}},
+	}
+
+	assert.Equal(t, []*models.CommentFeedback{
+		{Path: "a.go", Body: "down", Verdict: constants.FEEDBACK_REJECTED, Reason: "👎"},
+		{Path: "b.go", Body: "dismissed", Verdict: constants.FEEDBACK_REJECTED, Reason: "False positive: the map is never nil."},
+		{Path: "c.go", Body: "up", Verdict: constants.FEEDBACK_ACCEPTED, Reason: "👍"},
+		{Path: "f.go", Body: "won't fix", Verdict: constants.FEEDBACK_REJECTED, Reason: "That's by design."},
+	}, feedbackFor(posted, "ai-reviewer"))
+}
+
+func TestBuildSuppressions(t *testing.T) {
+	rejected := []*models.PRComment{
+		{FilePath: "internal/api/user.go", CommentType: "style", CommentText: "🔵 **Minor** · `style`\n\nConsider adding a doc comment to this exported function."},
+		{FilePath: "internal/db/user.go", CommentType: "style", CommentText: "🔵 **Minor** · `style`\n\nConsider adding a doc comment to the exported function.\n\n```suggestion\n// X does y.\n```"},
+		{FilePath: "internal/api/user.go", CommentType: "bug", CommentText: "🟠 **Major** · `bug`\n\nConsider adding a doc comment to this exported function."},
+		{FilePath: "main.go", CommentType: "style", CommentText: "🔵 **Minor** · `style`\n\nLine is too long."},
+	}
+
+	suppressions := buildSuppressions(rejected, config.FeedbackConfig{})
+	assert.Len(t, suppressions, 1)
+	assert.Equal(t, "style", suppressions[0].category)
+	assert.Equal(t, "internal", suppressions[0].dir)
+	assert.Equal(t, 2, suppressions[0].rejections)
+
+	t.Run("Success - matches by category, path and wording", func(t *testing.T) {
+		finding := models.ReviewComment{Category: "style", Message: "Consider adding a doc comment to this exported function"}
+		assert.NotNil(t, matchSuppression(suppressions, "internal/api/order.go", finding, config.FeedbackConfig{}))
+		assert.Nil(t, matchSuppression(suppressions, "cmd/main.go", finding, config.FeedbackConfig{}), "other directory")
+
+		finding.Category = "bug"
+		assert.Nil(t, matchSuppression(suppressions, "internal/api/order.go", finding, config.FeedbackConfig{}), "other category")
+
+		finding = models.ReviewComment{Category: "style", Message: "This function name shadows a builtin."}
+		assert.Nil(t, matchSuppression(suppressions, "internal/api/order.go", finding, config.FeedbackConfig{}), "other message")
+	})
+
+	t.Run("Success - MinRejections raises the bar", func(t *testing.T) {
+		assert.Empty(t, buildSuppressions(rejected, config.FeedbackConfig{MinRejections: 3}))
+	})
+}
+
+func TestCommonDir(t *testing.T) {
+	assert.Equal(t, "internal", commonDir("internal/api", "internal/db/pg"))
+	assert.Equal(t, "internal/api", commonDir("internal/api", "internal/api"))
+	assert.Equal(t, ".", commonDir("cmd", "internal"))
+	assert.Equal(t, ".", commonDir("x", "."))
+	assert.Equal(t, ".", commonDir(".", "x/y"))
+	assert.Equal(t, ".", commonDir("x", "y"))
+	assert.Equal(t, ".", commonDir(".", "."))
+	assert.Equal(t, "x", commonDir("x/a", "x/b"))
+	assert.Equal(t, ".", commonDir("internal/api", "internals/api"))
+}
+
+func TestProcessPullRequest_SuppressesRejectedFindings(t *testing.T) {
+	stubCloneRepo(t, nil)
+	ctx := context.Background()
+	feedback := config.FeedbackConfig{Enabled: true}
+	cfg := &config.Config{
+		LLM:       config.LLMConfig{ModelName: "test-model"},
+		PromptDir: testPromptDir,
+		Review:    config.ReviewConfig{Feedback: feedback},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockRepo := repository.NewMockVcsRepository(ctrl)
+	mockStore := storage.NewMockReviewStore(ctrl)
+
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,2 @@\n+func Load() {}\n+var x = 1"
+	mockStore.EXPECT().GetPRState(gomock.Any(), "test", "repo", 1).Return(&models.PRState{}, nil)
+	mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
+	mockRepo.EXPECT().ListPostedComments(gomock.Any(), "test", "repo", 1).Return([]*models.PostedComment{
+		{Path: "main.go", Body: "old comment", ThumbsDown: 1},
+	}, nil)
+	mockRepo.EXPECT().AuthenticatedLogin(gomock.Any()).Return("ai-reviewer", nil)
+	mockStore.EXPECT().RecordFeedback(gomock.Any(), "test", "repo", []*models.CommentFeedback{
+		{Path: "main.go", Body: "old comment", Verdict: constants.FEEDBACK_REJECTED, Reason: "👎"},
+	}).Return(nil)
+	mockStore.EXPECT().ListRejectedComments(gomock.Any(), "test", "repo", maxRejectedComments).Return([]*models.PRComment{
+		{FilePath: "main.go", CommentType: "style", CommentText: "🔵 **Minor** · `style`\n\nExported function Load should have a doc comment."},
+		{FilePath: "util.go", CommentType: "style", CommentText: "🔵 **Minor** · `style`\n\nExported function Save should have a doc comment."},
+	}, nil)
+	mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return(diff, nil)
+	mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).Return(&models.PRDetails{}, nil)
+	mockRepo.EXPECT().PostReview(gomock.Any(), "test", "repo", 1, gomock.Any(), "commit123").
+		DoAndReturn(func(ctx context.Context, owner, repo string, prNumber int, comments []*models.Comment, commitID string) error {
+			assert.Len(t, comments, 1)
+			assert.Equal(t, "bug", comments[0].Category)
+			return nil
+		})
+	mockStore.EXPECT().RecordReview(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockStore.EXPECT().FeedbackStats(gomock.Any(), "test", "repo").Return([]*models.FeedbackStats{{Category: "style", Posted: 4, Rejected: 2}}, nil)
+	mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), nil)
+	mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).Return(nil)
+
+	originalGenerate := genkitGenerate
+	genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		answer := `[{"line_content": "+func Load() {}", "message": "Exported function Load should have a doc comment.", "severity": "minor", "category": "style"},
+			{"line_content": "+var x = 1", "message": "x is never used.", "severity": "major", "category": "bug"}]`
+		return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(answer)}}}, nil
+	}
+	defer func() { genkitGenerate = originalGenerate }()
+
+	_, err := NewReviewService(mockRepo, mockStore, newTestGenkit(t), cfg).
+		ProcessPullRequest("", ctx, &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code-reviewer-bot/internal/diffparser"
+)
+
+// newBoundary returns the tag that delimits untrusted content in a prompt. It is
+// random per prompt so that PR content cannot close the block it is wrapped in.
+var newBoundary = func() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate prompt boundary: %w", err)
+	}
+	return "untrusted-" + hex.EncodeToString(b), nil
+}
+
+// injectionPatterns match phrases that address the reviewer instead of describing
+// the code. They are heuristics: a hit is reported, never acted upon.
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+|the\s+)?(previous|prior|above|earlier|preceding|your)\s+(instructions|prompts?|rules|directions)`),
+	regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(a|an|in)\b`),
+	regexp.MustCompile(`(?i)\b(new|updated|real)\s+(system\s+)?instructions\s*:`),
+	regexp.MustCompile(`(?i)\b(reveal|print|show|repeat)\s+(your|the)\s+(system\s+)?prompt\b`),
+	regexp.MustCompile(`(?i)\b(approve|merge|lgtm)\s+(this|the)\s+(pr|pull\s+request|change|diff)\b`),
+	regexp.MustCompile(`(?i)\b(do\s+not|don't|never)\s+(report|flag|comment\s+on|mention)\s+(any|this|these|the)\b`),
+	regexp.MustCompile(`(?i)\b(ai|llm|code)\s+reviewers?\s*(:|,)?\s*(please\s+)?(ignore|skip|approve)\b`),
+	regexp.MustCompile(`(?i)<\s*/?\s*(system|assistant|instructions?)\s*>`),
+}
+
+// injectionFinding is untrusted content that looks like it tries to instruct the
+// reviewer.
+type injectionFinding struct {
+	// Source names where the content came from: a file path or "PR description".
+	Source string
+	Phrase string
+}
+
+// detectInjection returns the first suspicious phrase in text, or "".
+func detectInjection(text string) string {
+	for _, pattern := range injectionPatterns {
+		if m := pattern.FindString(text); m != "" {
+			return m
+		}
+	}
+	return ""
+}
+
+// scanForInjection checks the added lines of every hunk and the PR's stated intent
+// for injection attempts. At most one finding is reported per source.
+func scanForInjection(chunks []*diffparser.DiffChunk, intent *pullRequestInput) []injectionFinding {
+	var findings []injectionFinding
+	seen := make(map[string]bool)
+	check := func(source, text string) {
+		if seen[source] {
+			return
+		}
+		if phrase := detectInjection(text); phrase != "" {
+			seen[source] = true
+			findings = append(findings, injectionFinding{Source: source, Phrase: phrase})
+		}
+	}
+	if intent != nil {
+		check("PR title", intent.Title)
+		check("PR description", intent.Description)
+		for _, issue := range intent.Issues {
+			check(fmt.Sprintf("issue #%d", issue.Number), issue.Title+"\n"+issue.Body)
+		}
+	}
+	for _, chunk := range chunks {
+		check(chunk.FilePath, chunk.FilePath+"\n"+addedLines(chunk.CodeSnippet))
+	}
+	return findings
+}
+
+// formatInjectionWarning renders the findings for the review summary.
+func formatInjectionWarning(findings []injectionFinding) string {
+	var b strings.Builder
+	b.WriteString("### ⚠️ Possible prompt injection\n\n")
+	b.WriteString("This pull request contains text that addresses the reviewer rather than describing the code. It was treated as data, but please review these places yourself:\n\n")
+	for _, f := range findings {
+		fmt.Fprintf(&b, "- `%s`: %q\n", f.Source, f.Phrase)
+	}
+	return b.String()
+}
+
+// chatCommandLine matches a line that other bots would read as a command, such as
+// "/approve" or "/label bug".
+var chatCommandLine = regexp.MustCompile(`(?m)^(\s*)(/[A-Za-z][\w-]*)`)
+
+// htmlComment matches hidden HTML comments, which the bot reserves for its own markers.
+var htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// sanitizeModelText makes model output safe to post. The model's text is only ever
+// posted as a comment, so it cannot approve, merge or label a PR itself; this keeps
+// it from doing so indirectly through bots that act on slash commands, and from
+// forging the hidden markers the bot uses to find its own comments.
+func sanitizeModelText(text string) string {
+	text = htmlComment.ReplaceAllString(text, "")
+	return chatCommandLine.ReplaceAllString(text, "$1`$2`")
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/coverage"
+	"code-reviewer-bot/internal/diffparser"
+)
+
+// errCoverageBelowThreshold fails a review whose added lines are less covered than
+// coverage.min_patch_coverage requires.
+var errCoverageBelowThreshold = errors.New("patch coverage is below the required minimum")
+
+// fileCoverage is the coverage of the lines a PR adds to one file. Only lines the
+// coverage reports instrument count.
+type fileCoverage struct {
+	path      string
+	covered   int
+	uncovered []int
+}
+
+func (f fileCoverage) total() int {
+	return f.covered + len(f.uncovered)
+}
+
+// checkPatchCoverage reads the configured coverage reports and returns the summary
+// note on the added lines they leave uncovered, or "" when the reports instrument
+// none of them. The error wraps errCoverageBelowThreshold when patch coverage is
+// below the configured minimum.
+func checkPatchCoverage(cfg config.CoverageConfig, chunks []*diffparser.DiffChunk, repoPath string) (string, error) {
+	report := loadCoverage(cfg.Reports, repoPath)
+	if report == nil {
+		return "### ⚠️ Patch Coverage\n\nNone of the configured coverage reports could be read.\n", nil
+	}
+	files := patchCoverage(report, chunks)
+	var covered, total int
+	for _, f := range files {
+		covered, total = covered+f.covered, total+f.total()
+	}
+	if total == 0 {
+		log.Println("The coverage reports instrument none of the added lines.")
+		return "", nil
+	}
+	percent := 100 * float64(covered) / float64(total)
+	log.Printf("Patch coverage: %.1f%% of %d added lines.", percent, total)
+	note := formatCoverageNote(files, percent, total, cfg.MinPatchCoverage)
+	if cfg.MinPatchCoverage > 0 && percent < cfg.MinPatchCoverage {
+		return note, fmt.Errorf("%w (%.1f%% < %g%%)", errCoverageBelowThreshold, percent, cfg.MinPatchCoverage)
+	}
+	return note, nil
+}
+
+// loadCoverage reads and merges the coverage reports; relative paths are read from
+// the clone. It returns nil when no report could be read.
+func loadCoverage(paths []string, repoPath string) *coverage.Report {
+	var merged *coverage.Report
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			resolved, err := cloneFile(repoPath, path)
+			if err != nil {
+				log.Printf("Skipping coverage report: %v", err)
+				continue
+			}
+			path = resolved
+		}
+		report, err := coverage.Load(path)
+		if err != nil {
+			log.Printf("Skipping coverage report: %v", err)
+			continue
+		}
+		if merged == nil {
+			merged = report
+		} else {
+			merged.Merge(report)
+		}
+	}
+	return merged
+}
+
+// cloneFile returns the real path of the named file of the clone. The PR author
+// controls the clone, so symlinks are resolved before the path is checked to stay
+// inside it; otherwise a committed link could make the bot read any file of the host.
+func cloneFile(repoPath, name string) (string, error) {
+	root, err := filepath.EvalSymlinks(repoPath)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, name))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s points outside the repository", name)
+	}
+	return path, nil
+}
+
+// patchCoverage returns, per changed file in the report, which added lines ran.
+func patchCoverage(report *coverage.Report, chunks []*diffparser.DiffChunk) []fileCoverage {
+	byPath := make(map[string]*fileCoverage)
+	var files []*fileCoverage
+	for _, chunk := range chunks {
+		lines, ok := report.Lines(chunk.FilePath)
+		if !ok {
+			continue
+		}
+		f := byPath[chunk.FilePath]
+		if f == nil {
+			f = &fileCoverage{path: chunk.FilePath}
+			byPath[chunk.FilePath] = f
+			files = append(files, f)
+		}
+		for _, line := range chunk.AddedLineNumbers(false) {
+			covered, instrumented := lines[line]
+			switch {
+			case !instrumented:
+			case covered:
+				f.covered++
+			default:
+				f.uncovered = append(f.uncovered, line)
+			}
+		}
+	}
+
+	var result []fileCoverage
+	for _, f := range files {
+		if f.total() > 0 {
+			slices.Sort(f.uncovered)
+			result = append(result, *f)
+		}
+	}
+	slices.SortFunc(result, func(a, b fileCoverage) int { return strings.Compare(a.path, b.path) })
+	return result
+}
+
+func formatCoverageNote(files []fileCoverage, percent float64, total int, minimum float64) string {
+	var b strings.Builder
+	b.WriteString("### 📊 Patch Coverage\n\n")
+	b.WriteString(fmt.Sprintf("**%.1f%%** of the %d added lines the coverage reports instrument were run by tests", percent, total))
+	if minimum > 0 {
+		b.WriteString(fmt.Sprintf(" (minimum %g%%)", minimum))
+	}
+	b.WriteString(".\n\n")
+	b.WriteString("| File | Coverage | Uncovered lines |\n| --- | --- | --- |\n")
+	for _, f := range files {
+		uncovered := "—"
+		if len(f.uncovered) > 0 {
+			uncovered = formatLineRanges(f.uncovered)
+		}
+		b.WriteString(fmt.Sprintf("| `%s` | %.1f%% (%d/%d) | %s |\n",
+			f.path, 100*float64(f.covered)/float64(f.total()), f.covered, f.total(), uncovered))
+	}
+	return b.String()
+}
+
+// formatLineRanges joins sorted line numbers into ranges, e.g. "3-5, 9".
+func formatLineRanges(lines []int) string {
+	var ranges []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(lines[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPatchCoverage(t *testing.T) {
+	repoPath := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "coverage.out"), []byte("mode: set\n"+
+		"shop/cart/cart.go:3.20,6.2 2 1\n"+
+		"shop/cart/cart.go:8.20,12.2 3 0\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "lcov.info"), []byte("SF:web/app.ts\nDA:2,1\nend_of_record\n"), 0o644))
+	chunks := diffparser.Parse("diff --git a/cart/cart.go b/cart/cart.go\n--- a/cart/cart.go\n+++ b/cart/cart.go\n" +
+		"@@ -3,3 +3,4 @@\n func Total() int {\n+\tn := 1\n-\treturn 0\n+\treturn n\n }\n" +
+		"@@ -8,0 +8,5 @@\n+func Clear() {\n+\tif true {\n+\t\treturn\n+\t}\n+}\n" +
+		"diff --git a/web/app.ts b/web/app.ts\n--- a/web/app.ts\n+++ b/web/app.ts\n@@ -1,1 +1,2 @@\n import x\n+run()\n" +
+		"diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1,0 +1,1 @@\n+# Shop\n")
+
+	t.Run("Success - reports uncovered added lines per file", func(t *testing.T) {
+		note, err := checkPatchCoverage(config.CoverageConfig{Reports: []string{"coverage.out", "lcov.info"}}, chunks, repoPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "### 📊 Patch Coverage\n\n"+
+			"**37.5%** of the 8 added lines the coverage reports instrument were run by tests.\n\n"+
+			"| File | Coverage | Uncovered lines |\n| --- | --- | --- |\n"+
+			"| `cart/cart.go` | 28.6% (2/7) | 8-12 |\n"+
+			"| `web/app.ts` | 100.0% (1/1) | — |\n", note)
+	})
+
+	t.Run("Failure - patch coverage below the minimum", func(t *testing.T) {
+		note, err := checkPatchCoverage(config.CoverageConfig{Reports: []string{"coverage.out", "missing.info"}, MinPatchCoverage: 80}, chunks, repoPath)
+		assert.ErrorIs(t, err, errCoverageBelowThreshold)
+		assert.ErrorContains(t, err, "(28.6% < 80%)")
+		assert.Contains(t, note, "were run by tests (minimum 80%).")
+	})
+
+	t.Run("Failure - no readable report", func(t *testing.T) {
+		note, err := checkPatchCoverage(config.CoverageConfig{Reports: []string{"missing.info"}, MinPatchCoverage: 80}, chunks, repoPath)
+		assert.NoError(t, err)
+		assert.Contains(t, note, "None of the configured coverage reports could be read.")
+	})
+}
+
+func TestCloneFile(t *testing.T) {
+	repoPath := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret")
+	assert.NoError(t, os.WriteFile(outside, []byte("token"), 0o600))
+	assert.NoError(t, os.Mkdir(filepath.Join(repoPath, "build"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "build", "lcov.info"), nil, 0o644))
+	assert.NoError(t, os.Symlink(filepath.Join("build", "lcov.info"), filepath.Join(repoPath, "lcov.info")))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(repoPath, "coverage.out")))
+	assert.NoError(t, os.Symlink(filepath.Dir(outside), filepath.Join(repoPath, "ci")))
+
+	t.Run("Success - links inside the repository", func(t *testing.T) {
+		path, err := cloneFile(repoPath, "lcov.info")
+		assert.NoError(t, err)
+		assert.Equal(t, "lcov.info", filepath.Base(path))
+		assert.Equal(t, "build", filepath.Base(filepath.Dir(path)))
+	})
+
+	t.Run("Failure - links that leave the repository", func(t *testing.T) {
+		_, err := cloneFile(repoPath, "coverage.out")
+		assert.ErrorContains(t, err, "points outside the repository")
+		_, err = cloneFile(repoPath, "ci/secret")
+		assert.ErrorContains(t, err, "points outside the repository")
+	})
+}
+
+func TestFormatLineRanges(t *testing.T) {
+	assert.Equal(t, "3-5, 9, 11-12", formatLineRanges([]int{3, 4, 5, 9, 11, 12}))
+	assert.Equal(t, "7", formatLineRanges([]int{7}))
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code-reviewer-bot/internal/models"
+)
+
+const (
+	// maxIntentBytes caps the PR description and each linked issue body in the prompt.
+	maxIntentBytes = 4000
+	// maxLinkedIssues caps how many issues referenced by the PR are fetched.
+	maxLinkedIssues = 3
+)
+
+// linkedIssuePattern matches issue references in a PR description, such as
+// "Fixes #12", "closes: #7" or "Refs #3".
+var linkedIssuePattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?|refs?)\b:?\s+#(\d+)\b`)
+
+// pullRequestInput is the author's stated intent, passed to the review prompt so the
+// model can flag code that contradicts it or goes beyond its scope.
+type pullRequestInput struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Issues      []issueInput `json:"issues,omitempty"`
+}
+
+type issueInput struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body,omitempty"`
+}
+
+// loadPRIntent returns the PR's title and description and the issues it links to.
+// The title and description the webhook or CLI already read are reused; only
+// details without a title are fetched. It returns nil when there is nothing to tell
+// the model.
+func (s *ReviewService) loadPRIntent(ctx context.Context, prDetails *models.PRDetails) *pullRequestInput {
+	title, body := prDetails.Title, prDetails.Body
+	if title == "" {
+		if pr, err := s.repo.GetPullRequest(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber); err != nil {
+			log.Printf("Warning: could not fetch PR description: %v", err)
+		} else {
+			title, body = pr.Title, pr.Body
+		}
+	}
+	title, body = strings.TrimSpace(title), strings.TrimSpace(body)
+	if title == "" && body == "" {
+		return nil
+	}
+
+	intent := &pullRequestInput{Title: title, Description: truncateText(body, maxIntentBytes)}
+	for _, number := range linkedIssues(body, prDetails.PRNumber) {
+		issue, err := s.repo.GetIssue(ctx, prDetails.Owner, prDetails.Repo, number)
+		if err != nil {
+			log.Printf("Warning: could not fetch linked issue #%d: %v", number, err)
+			continue
+		}
+		intent.Issues = append(intent.Issues, issueInput{
+			Number: issue.Number,
+			Title:  strings.TrimSpace(issue.Title),
+			Body:   truncateText(strings.TrimSpace(issue.Body), maxIntentBytes),
+		})
+	}
+	return intent
+}
+
+// linkedIssues returns the distinct issue numbers referenced in a PR description, in
+// order of appearance and at most maxLinkedIssues. The PR itself is skipped.
+func linkedIssues(body string, prNumber int) []int {
+	var numbers []int
+	seen := map[int]bool{prNumber: true}
+	for _, m := range linkedIssuePattern.FindAllStringSubmatch(body, -1) {
+		number, err := strconv.Atoi(m[1])
+		if err != nil || seen[number] {
+			continue
+		}
+		seen[number] = true
+		numbers = append(numbers, number)
+		if len(numbers) == maxLinkedIssues {
+			break
+		}
+	}
+	return numbers
+}
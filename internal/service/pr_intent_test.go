@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/repository"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLinkedIssues(t *testing.T) {
+	t.Run("Success - closing keywords and references", func(t *testing.T) {
+		body := "Fixes #12 and closes: #7.\nRefs #12, resolved #3, see #99, fixes #1"
+		assert.Equal(t, []int{12, 7, 3}, linkedIssues(body, 1))
+	})
+
+	t.Run("Success - skips the PR itself and ignores bare numbers", func(t *testing.T) {
+		assert.Equal(t, []int{4}, linkedIssues("Fixes #5. Related to #6. Resolves #4", 5))
+	})
+
+	t.Run("Success - caps the number of issues", func(t *testing.T) {
+		assert.Len(t, linkedIssues("fix #1 fix #2 fix #3 fix #4", 0), maxLinkedIssues)
+	})
+}
+
+func TestLoadPRIntent(t *testing.T) {
+	ctx := context.Background()
+	prDetails := &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1}
+
+	t.Run("Success - fetches the description and linked issues", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().GetPullRequest(ctx, "test", "repo", 1).
+			Return(&models.PRDetails{Title: "Add retries", Body: "Fixes #3\n\n" + strings.Repeat("x", maxIntentBytes)}, nil)
+		mockRepo.EXPECT().GetIssue(ctx, "test", "repo", 3).
+			Return(&models.Issue{Number: 3, Title: "Requests time out", Body: " Retry on 503. "}, nil)
+
+		intent := NewReviewService(mockRepo, nil, nil, &config.Config{}).loadPRIntent(ctx, prDetails)
+		assert.Equal(t, "Add retries", intent.Title)
+		assert.True(t, strings.HasSuffix(intent.Description, truncatedMarker))
+		assert.Equal(t, []issueInput{{Number: 3, Title: "Requests time out", Body: "Retry on 503."}}, intent.Issues)
+	})
+
+	t.Run("Success - reuses the webhook details without fetching the PR", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().GetIssue(ctx, "test", "repo", 3).Return(&models.Issue{Number: 3, Title: "Requests time out"}, nil)
+
+		details := &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1, Title: "Webhook title", Body: "Fixes #3"}
+		intent := NewReviewService(mockRepo, nil, nil, &config.Config{}).loadPRIntent(ctx, details)
+		assert.Equal(t, &pullRequestInput{Title: "Webhook title", Description: "Fixes #3", Issues: []issueInput{{Number: 3, Title: "Requests time out"}}}, intent)
+	})
+
+	t.Run("Success - no intent without title or description", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().GetPullRequest(ctx, "test", "repo", 2).Return(&models.PRDetails{}, nil)
+
+		intent := NewReviewService(mockRepo, nil, nil, &config.Config{}).loadPRIntent(ctx, &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 2})
+		assert.Nil(t, intent)
+	})
+
+	t.Run("Failure - the PR cannot be fetched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().GetPullRequest(ctx, "test", "repo", 1).Return(nil, errors.New("rate limited"))
+
+		intent := NewReviewService(mockRepo, nil, nil, &config.Config{}).loadPRIntent(ctx, prDetails)
+		assert.Nil(t, intent)
+	})
+
+	t.Run("Failure - linked issue cannot be fetched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().GetPullRequest(ctx, "test", "repo", 1).Return(&models.PRDetails{Title: "Add retries", Body: "Fixes #3"}, nil)
+		mockRepo.EXPECT().GetIssue(ctx, "test", "repo", 3).Return(nil, errors.New("not found"))
+
+		intent := NewReviewService(mockRepo, nil, nil, &config.Config{}).loadPRIntent(ctx, prDetails)
+		assert.Equal(t, &pullRequestInput{Title: "Add retries", Description: "Fixes #3"}, intent)
+	})
+}
+
+func TestAnalyzeChunk_PRIntent(t *testing.T) {
+	cfg := &config.Config{LLM: config.LLMConfig{ModelName: "test-model"}, PromptDir: testPromptDir}
+	reviewService := NewReviewService(nil, nil, newTestGenkit(t), cfg)
+
+	var rendered string
+	originalGenerate := genkitGenerate
+	genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		rendered = req.Messages[len(req.Messages)-1].Text()
+		return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+	}
+	defer func() { genkitGenerate = originalGenerate }()
+
+	t.Run("Success - renders the title, description and linked issues", func(t *testing.T) {
+		run := newReviewRun(cfg)
+		run.intent = &pullRequestInput{
+			Title:       "Add retries",
+			Description: "Retry <5xx> responses",
+			Issues:      []issueInput{{Number: 3, Title: "Requests time out", Body: "Retry on 503."}},
+		}
+		_, _, err := reviewService.analyzeChunk(context.Background(), run, &diffparser.DiffChunk{FilePath: "main.go", CodeSnippet: "+retry()"})
+		assert.NoError(t, err)
+		assert.Contains(t, rendered, "Title: Add retries\nDescription:\nRetry <5xx> responses\n")
+		assert.Contains(t, rendered, "Linked issue #3: Requests time out\nRetry on 503.\n")
+	})
+
+	t.Run("Success - omits the section without intent", func(t *testing.T) {
+		_, _, err := reviewService.analyzeChunk(context.Background(), newReviewRun(cfg), &diffparser.DiffChunk{FilePath: "main.go", CodeSnippet: "+retry()"})
+		assert.NoError(t, err)
+		assert.NotContains(t, rendered, "Pull request intent")
+	})
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+)
+
+// summaryInput is the input of the summary prompt.
+type summaryInput struct {
+	Boundary    string            `json:"boundary"`
+	Language    string            `json:"language,omitempty"`
+	PullRequest *pullRequestInput `json:"pullRequest,omitempty"`
+	Files       []summaryFile     `json:"files"`
+	Findings    []summaryFinding  `json:"findings,omitempty"`
+}
+
+// summaryFile is one changed file with its hunks joined. Diff is empty for files
+// that did not fit the summary's token budget.
+type summaryFile struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Diff      string `json:"diff,omitempty"`
+}
+
+// summaryFinding is a posted review comment, so risk areas can point at them.
+type summaryFinding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Category string `json:"category"`
+}
+
+// prSummary is the model's summary of a pull request.
+type prSummary struct {
+	Overview string `json:"overview"`
+	Files    []struct {
+		Path        string `json:"path"`
+		Description string `json:"description"`
+	} `json:"files"`
+	Risks []string `json:"risks"`
+	Focus []string `json:"focus"`
+}
+
+// summarizePullRequest asks the model for an overview of all hunks and renders it as
+// a comment. Files keep the order of chunks, so when the diff budget runs out it is
+// the tests and docs that are described from their names alone.
+func (s *ReviewService) summarizePullRequest(ctx context.Context, run *reviewRun, chunks []*diffparser.DiffChunk, comments []*models.Comment) (string, error) {
+	boundary, err := newBoundary()
+	if err != nil {
+		return "", err
+	}
+	files := summaryFiles(chunks, run.cfg.Review.Summary.MaxDiffTokens)
+	input := summaryInput{
+		Boundary:    boundary,
+		Language:    run.cfg.Review.Language,
+		PullRequest: run.intent,
+		Files:       files,
+	}
+	for _, c := range comments {
+		input.Findings = append(input.Findings, summaryFinding{Path: c.Path, Line: c.Line, Severity: c.Severity, Category: c.Category})
+	}
+
+	req, err := s.renderPrompt(ctx, run, constants.PROMPT_SUMMARY, input)
+	if err != nil {
+		return "", err
+	}
+	res, _, err := s.generate(ctx, run, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate summary: %w", err)
+	}
+	summary, err := parseSummary(res.Text())
+	if err != nil {
+		return "", fmt.Errorf("failed to parse summary: %w", err)
+	}
+	return formatPRSummary(summary, files), nil
+}
+
+// summaryFiles groups the chunks by file, counting added and removed lines. Hunks are
+// included until maxTokens (estimated) is used up; 0 disables the limit.
+func summaryFiles(chunks []*diffparser.DiffChunk, maxTokens int) []summaryFile {
+	var files []summaryFile
+	index := make(map[string]int)
+	for _, chunk := range chunks {
+		i, ok := index[chunk.FilePath]
+		if !ok {
+			i = len(files)
+			index[chunk.FilePath] = i
+			files = append(files, summaryFile{Path: chunk.FilePath})
+		}
+		for _, line := range strings.Split(chunk.CodeSnippet, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				files[i].Additions++
+			case strings.HasPrefix(line, "-"):
+				files[i].Deletions++
+			}
+		}
+	}
+
+	used := 0
+	for _, chunk := range chunks {
+		tokens := estimateTokens(chunk.CodeSnippet)
+		if maxTokens > 0 && used+tokens > maxTokens {
+			continue
+		}
+		used += tokens
+		f := &files[index[chunk.FilePath]]
+		if f.Diff != "" {
+			f.Diff += "\n"
+		}
+		f.Diff += chunk.CodeSnippet
+	}
+	return files
+}
+
+func parseSummary(text string) (*prSummary, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no JSON object in response")
+	}
+	var summary prSummary
+	if err := json.Unmarshal([]byte(text[start:end+1]), &summary); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(summary.Overview) == "" {
+		return nil, fmt.Errorf("summary has no overview")
+	}
+	return &summary, nil
+}
+
+// formatPRSummary renders the summary. The file table lists every changed file from
+// the diff, whether or not the model described it; descriptions for paths that are
+// not in the diff are dropped.
+func formatPRSummary(summary *prSummary, files []summaryFile) string {
+	descriptions := make(map[string]string)
+	for _, f := range summary.Files {
+		descriptions[f.Path] = f.Description
+	}
+
+	var b strings.Builder
+	b.WriteString("## 📋 Pull Request Summary\n\n")
+	b.WriteString(sanitizeModelText(strings.TrimSpace(summary.Overview)))
+	b.WriteString("\n\n### Changes\n\n")
+	b.WriteString("| File | Lines | Description |\n")
+	b.WriteString("| --- | --- | --- |\n")
+	for _, f := range files {
+		description := tableCell(sanitizeModelText(descriptions[f.Path]))
+		if description == "" {
+			description = "—"
+		}
+		fmt.Fprintf(&b, "| `%s` | +%d / -%d | %s |\n", f.Path, f.Additions, f.Deletions, description)
+	}
+	writeSummaryList(&b, "Risk Areas", summary.Risks)
+	writeSummaryList(&b, "Reviewer Focus", summary.Focus)
+	return b.String()
+}
+
+func writeSummaryList(b *strings.Builder, title string, items []string) {
+	var lines []string
+	for _, item := range items {
+		if item = strings.Join(strings.Fields(sanitizeModelText(item)), " "); item != "" {
+			lines = append(lines, item)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, line := range lines {
+		fmt.Fprintf(b, "- %s\n", line)
+	}
+}
+
+// tableCell flattens text into a single markdown table cell.
+func tableCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", "\\|")
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/repository"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSummaryFiles(t *testing.T) {
+	chunks := []*diffparser.DiffChunk{
+		{FilePath: "main.go", CodeSnippet: "@@ -1,2 +1,2 @@\n-a\n+b\n c"},
+		{FilePath: "util.go", CodeSnippet: "@@ -0,0 +1,2 @@\n+x\n+y"},
+		{FilePath: "main.go", CodeSnippet: "@@ -9,0 +9,1 @@\n+d"},
+	}
+
+	t.Run("Success - groups hunks by file", func(t *testing.T) {
+		files := summaryFiles(chunks, 0)
+		assert.Equal(t, []summaryFile{
+			{Path: "main.go", Additions: 2, Deletions: 1, Diff: "@@ -1,2 +1,2 @@\n-a\n+b\n c\n@@ -9,0 +9,1 @@\n+d"},
+			{Path: "util.go", Additions: 2, Diff: "@@ -0,0 +1,2 @@\n+x\n+y"},
+		}, files)
+	})
+
+	t.Run("Success - leaves out hunks beyond the budget", func(t *testing.T) {
+		files := summaryFiles(chunks, 10)
+		assert.Equal(t, "@@ -1,2 +1,2 @@\n-a\n+b\n c", files[0].Diff)
+		assert.Empty(t, files[1].Diff)
+		assert.Equal(t, 2, files[1].Additions)
+	})
+}
+
+func TestFormatPRSummary(t *testing.T) {
+	summary, err := parseSummary("Here you go:\n" + `{
+		"overview": "Adds retries to the client.",
+		"files": [
+			{"path": "client.go", "description": "Wraps calls in a retry | backoff loop.\nAlso logs."},
+			{"path": "other.go", "description": "Not in the diff."}
+		],
+		"risks": ["Retries are not idempotent for POST.", "  "],
+		"focus": []
+	}`)
+	assert.NoError(t, err)
+
+	files := []summaryFile{{Path: "client.go", Additions: 10, Deletions: 2}, {Path: "client_test.go", Additions: 30}}
+	assert.Equal(t, "## 📋 Pull Request Summary\n\n"+
+		"Adds retries to the client.\n\n"+
+		"### Changes\n\n"+
+		"| File | Lines | Description |\n"+
+		"| --- | --- | --- |\n"+
+		"| `client.go` | +10 / -2 | Wraps calls in a retry \\| backoff loop. Also logs. |\n"+
+		"| `client_test.go` | +30 / -0 | — |\n"+
+		"\n### Risk Areas\n\n"+
+		"- Retries are not idempotent for POST.\n", formatPRSummary(summary, files))
+
+	t.Run("Failure - summary without an overview", func(t *testing.T) {
+		_, err := parseSummary(`{"overview": "", "files": []}`)
+		assert.ErrorContains(t, err, "no overview")
+	})
+}
+
+func TestProcessPullRequest_Summary(t *testing.T) {
+	stubCloneRepo(t, nil)
+	stubBoundary(t)
+	ctx := context.Background()
+	cfg := &config.Config{
+		LLM:       config.LLMConfig{ModelName: "test-model"},
+		PromptDir: testPromptDir,
+		Review:    config.ReviewConfig{Summary: config.SummaryConfig{Enabled: true}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockRepo := repository.NewMockVcsRepository(ctrl)
+	reviewService := NewReviewService(mockRepo, nil, newTestGenkit(t), cfg)
+
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,1 @@\n+ some change"
+	mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
+	mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return(diff, nil)
+	mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).Return(&models.PRDetails{Title: "Add a change"}, nil)
+	mockRepo.EXPECT().PostReview(gomock.Any(), "test", "repo", 1, gomock.Any(), "commit123").Return(nil)
+	mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), nil)
+	mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, owner, repo string, prNumber int, body string) error {
+			assert.True(t, strings.HasPrefix(body, constants.SUMMARY_COMMENT_MARKER+"\n## 📋 Pull Request Summary\n\nChanges main."))
+			assert.Contains(t, body, "| `main.go` | +1 / -0 | Adds a change. |")
+			return nil
+		})
+
+	var summaryPrompt string
+	originalGenerate := genkitGenerate
+	genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		text := req.Messages[len(req.Messages)-1].Text()
+		answer := `[{"line_content": "+ some change", "message": "A valid comment", "severity": "major", "category": "bug"}]`
+		if strings.Contains(text, "summary of a pull request") {
+			summaryPrompt = text
+			answer = `{"overview": "Changes main.", "files": [{"path": "main.go", "description": "Adds a change."}]}`
+		}
+		return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(answer)}}}, nil
+	}
+	defer func() { genkitGenerate = originalGenerate }()
+
+	_, err := reviewService.ProcessPullRequest("", ctx, &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1})
+	assert.NoError(t, err)
+	assert.Contains(t, summaryPrompt, "<untrusted-test>\n--- main.go (+1 -0) ---\n@@ -1,0 +1,1 @@\n+ some change\n</untrusted-test>")
+	assert.Contains(t, summaryPrompt, "- main.go:1 major bug\n")
+	assert.Contains(t, summaryPrompt, "Title: Add a change\n")
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+)
+
+// loadRepoConfig reads .ai-review.yaml from the PR's base branch and merges it over
+// the global config. Reading it from the base branch keeps a PR from loosening its
+// own review. An invalid file is reported in the summary comment and ignored.
+func (s *ReviewService) loadRepoConfig(ctx context.Context, run *reviewRun, prDetails *models.PRDetails, repoPath string) *config.Config {
+	data, found, err := readBaseFile(ctx, repoPath, prDetails.BaseBranch, constants.REPO_CONFIG_FILE)
+	if err != nil {
+		log.Printf("Warning: could not read %s: %v", constants.REPO_CONFIG_FILE, err)
+		return s.cfg
+	}
+	if !found {
+		return s.cfg
+	}
+
+	rc, err := config.ParseRepoConfig(data)
+	if err != nil {
+		log.Printf("Ignoring invalid %s: %v", constants.REPO_CONFIG_FILE, err)
+		run.addNote(formatRepoConfigWarning(err))
+		return s.cfg
+	}
+	log.Printf("Applying %s overrides: %s", constants.REPO_CONFIG_FILE, rc)
+	return s.cfg.WithRepoConfig(rc)
+}
+
+func formatRepoConfigWarning(err error) string {
+	return fmt.Sprintf("### ⚠️ Invalid `%s`\n\nThe repository configuration on the base branch could not be applied, so this review uses the global settings.\n\n```\n%v\n```",
+		constants.REPO_CONFIG_FILE, err)
+}
+
+// filterDiff drops the sections of a unified diff whose files are excluded by the
+// path filters.
+func filterDiff(diff string, filter config.PathFilterConfig) string {
+	if len(filter.Include) == 0 && len(filter.Exclude) == 0 {
+		return diff
+	}
+	var b strings.Builder
+	keep := true
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			keep = filter.Allows(diffFilePath(line))
+			if !keep {
+				log.Printf("Skipping %s: excluded by path filters", diffFilePath(line))
+			}
+		}
+		if keep {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// diffFilePath returns the new path from a "diff --git a/<old> b/<new>" header.
+func diffFilePath(header string) string {
+	header = strings.TrimRight(header, "\r\n")
+	if i := strings.LastIndex(header, " b/"); i != -1 {
+		return header[i+len(" b/"):]
+	}
+	return strings.TrimPrefix(header, "diff --git ")
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRepoConfig(t *testing.T) {
+	ctx := context.Background()
+	prDetails := &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1, BaseBranch: "main"}
+	cfg := &config.Config{Review: config.ReviewConfig{MinSeverityToPost: "minor"}}
+
+	t.Run("Success - merges the repository overrides", func(t *testing.T) {
+		stubBaseFiles(t, map[string]string{".ai-review.yaml": "min_severity_to_post: major\ndisabled_checks: [tests]\npaths:\n  exclude: [\"gen/**\"]\n"})
+		reviewService := NewReviewService(nil, nil, nil, cfg)
+
+		merged := reviewService.loadRepoConfig(ctx, newReviewRun(cfg), prDetails, t.TempDir())
+		assert.Equal(t, "major", merged.Review.MinSeverityToPost)
+		assert.False(t, merged.Review.CheckEnabled("tests"))
+		assert.False(t, merged.Review.Paths.Allows("gen/api.pb.go"))
+		assert.Equal(t, "minor", cfg.Review.MinSeverityToPost, "global config must not change")
+	})
+
+	t.Run("Success - uses the global config when the file is absent", func(t *testing.T) {
+		stubBaseFiles(t, nil)
+		reviewService := NewReviewService(nil, nil, nil, cfg)
+		assert.Same(t, cfg, reviewService.loadRepoConfig(ctx, newReviewRun(cfg), prDetails, t.TempDir()))
+	})
+
+	t.Run("Failure - reports and ignores an invalid file", func(t *testing.T) {
+		stubBaseFiles(t, map[string]string{".ai-review.yaml": "min_severity: major\n"})
+		reviewService := NewReviewService(nil, nil, nil, cfg)
+		run := newReviewRun(cfg)
+
+		assert.Same(t, cfg, reviewService.loadRepoConfig(ctx, run, prDetails, t.TempDir()))
+		assert.Len(t, run.notes, 1)
+		assert.Contains(t, run.notes[0], "Invalid `.ai-review.yaml`")
+		assert.Contains(t, run.notes[0], "field min_severity not found")
+	})
+}
+
+func TestFilterDiff(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n+a\n" +
+		"diff --git a/gen/api.pb.go b/gen/api.pb.go\n--- a/gen/api.pb.go\n+++ b/gen/api.pb.go\n@@ -1 +1 @@\n+b\n"
+
+	t.Run("Success - drops excluded files", func(t *testing.T) {
+		filtered := filterDiff(diff, config.PathFilterConfig{Exclude: []string{"gen/**"}})
+		assert.Contains(t, filtered, "b/main.go")
+		assert.NotContains(t, filtered, "api.pb.go")
+	})
+
+	t.Run("Success - keeps only included files", func(t *testing.T) {
+		filtered := filterDiff(diff, config.PathFilterConfig{Include: []string{"gen/**"}})
+		assert.NotContains(t, filtered, "main.go\n")
+		assert.Contains(t, filtered, "+b\n")
+	})
+
+	t.Run("Success - returns the diff unchanged without filters", func(t *testing.T) {
+		assert.Equal(t, diff, filterDiff(diff, config.PathFilterConfig{}))
+	})
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/codesearch"
+	"code-reviewer-bot/internal/goindex"
+	"code-reviewer-bot/internal/models"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// reviewRun holds the state of a single ProcessPullRequest call. The ReviewService is
+// shared between concurrent webhook deliveries, so anything accumulated while
+// reviewing one pull request lives here instead of on the service.
+type reviewRun struct {
+	cfg             *config.Config
+	usage           models.TokenUsage
+	llmCalls        int
+	suggestionStyle string
+	// discarded counts findings rejected by the verifier.
+	discarded int
+	// prompts holds the IDs ("name@version") of the prompts rendered during the run.
+	prompts map[string]bool
+	// styleGuides are the project documents passed to every review prompt.
+	styleGuides []styleGuide
+	// intent is the PR's title, description and linked issues.
+	intent *pullRequestInput
+	// symbols indexes the Go declarations of the PR head; nil when not in use.
+	symbols *goindex.Index
+	// search is the keyword index of the clone; nil when code search is off.
+	search *codesearch.Index
+	// injections are suspected prompt-injection attempts, reported in the summary.
+	injections []injectionFinding
+	// suppressions are the kinds of findings developers of the repository keep
+	// rejecting; suppressed counts the findings they held back.
+	suppressions []*suppression
+	suppressed   int
+	// prSummary, notes and status make up the sticky summary comment: the PR
+	// walkthrough, the reports of individual checks in the order they ran, and
+	// the outcome of the review.
+	prSummary string
+	notes     []string
+	status    string
+}
+
+func newReviewRun(cfg *config.Config) *reviewRun {
+	return &reviewRun{cfg: cfg, prompts: make(map[string]bool)}
+}
+
+func (r *reviewRun) addNote(note string) {
+	r.notes = append(r.notes, note)
+}
+
+func (r *reviewRun) usePrompt(id string) {
+	r.prompts[id] = true
+}
+
+// promptVersion lists the prompt revisions used during the run, e.g. "review@2, verification@1".
+func (r *reviewRun) promptVersion() string {
+	ids := slices.Sorted(maps.Keys(r.prompts))
+	return strings.Join(ids, ", ")
+}
+
+// result builds the outcome to persist for the run.
+func (r *reviewRun) result(status string, comments []*models.Comment) *models.ReviewResult {
+	return &models.ReviewResult{
+		Status:        status,
+		Comments:      comments,
+		Usage:         r.usage,
+		Discarded:     r.discarded,
+		PromptVersion: r.promptVersion(),
+	}
+}
+
+// recordUsage adds the token usage of one model response to the run totals and
+// prices it using the configured price table.
+func (r *reviewRun) recordUsage(model string, usage *ai.GenerationUsage) {
+	if usage == nil {
+		return
+	}
+	r.usage.InputTokens += usage.InputTokens
+	r.usage.OutputTokens += usage.OutputTokens
+	if price, ok := r.cfg.LLM.Pricing[model]; ok {
+		r.usage.Cost += float64(usage.InputTokens)/1e6*price.InputPerMillion +
+			float64(usage.OutputTokens)/1e6*price.OutputPerMillion
+	}
+}
+
+func formatUsage(usage models.TokenUsage) string {
+	return fmt.Sprintf("Token usage: %d input / %d output, estimated cost $%.4f",
+		usage.InputTokens, usage.OutputTokens, usage.Cost)
+}
@@ -1,21 +1,23 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
 	"log"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 
 	"code-reviewer-bot/config"
 	"code-reviewer-bot/constants"
 	"code-reviewer-bot/internal/diffparser"
 	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/prompts"
 	"code-reviewer-bot/internal/repository"
+	"code-reviewer-bot/internal/storage"
 	"code-reviewer-bot/internal/utils"
 
 	"github.com/firebase/genkit/go/ai"
@@ -24,22 +26,59 @@ import (
 
 // ReviewService encapsulates the core business logic for reviewing a pull request.
 type ReviewService struct {
-	repo repository.VcsRepository
-	g    *genkit.Genkit
-	cfg  *config.Config
+	repo    repository.VcsRepository
+	store   storage.ReviewStore
+	g       *genkit.Genkit
+	prompts *prompts.Set
+	cfg     *config.Config
 }
 
-var genkitGenerate = genkit.Generate
+var (
+	genkitGenerate = genkit.GenerateWithRequest
+	cloneRepo      = utils.CloneRepo
+	checkoutHead   = utils.CheckoutPullRequestHead
+	readBaseFile   = utils.ReadFileAtBase
+	listBaseFiles  = utils.ListFilesAtBase
+)
 
-// NewReviewService creates a new service instance.
-func NewReviewService(vcsRepo repository.VcsRepository, g *genkit.Genkit, cfg *config.Config) *ReviewService {
-	return &ReviewService{repo: vcsRepo, g: g, cfg: cfg}
+// NewReviewService creates a new service instance. A nil store disables persistence.
+func NewReviewService(vcsRepo repository.VcsRepository, store storage.ReviewStore, g *genkit.Genkit, cfg *config.Config) *ReviewService {
+	return &ReviewService{repo: vcsRepo, store: store, g: g, prompts: prompts.NewSet(g, cfg.PromptDir), cfg: cfg}
 }
 
-// ProcessPullRequest is the main orchestration method.
+// ProcessPullRequest is the main orchestration method. It does nothing for pull
+// requests whose automatic reviews were paused with /pause.
 func (s *ReviewService) ProcessPullRequest(baseUrl string, ctx context.Context, prDetails *models.PRDetails) (string, error) {
+	return s.processPullRequest(baseUrl, ctx, prDetails, false)
+}
+
+// processPullRequest reviews a pull request; onDemand marks reviews asked for with
+// /review, which run even while automatic reviews are paused.
+func (s *ReviewService) processPullRequest(baseUrl string, ctx context.Context, prDetails *models.PRDetails, onDemand bool) (result string, err error) {
+	state := s.loadPRState(ctx, prDetails)
+	if state.Paused && !onDemand {
+		log.Printf("Skipping PR #%d in %s/%s: automatic reviews are paused", prDetails.PRNumber, prDetails.Owner, prDetails.Repo)
+		return "Automatic reviews are paused.", nil
+	}
 	log.Printf("Starting review for PR #%d in %s/%s", prDetails.PRNumber, prDetails.Owner, prDetails.Repo)
 	var allComments []*models.Comment
+	run := newReviewRun(s.cfg)
+	run.suggestionStyle = suggestionStyleFor(baseUrl)
+	// A failed run is not reported by the callers with its usage, so it is logged here.
+	defer func() {
+		if err != nil {
+			log.Printf("Review failed. %s.", formatUsage(run.usage))
+		}
+	}()
+
+	// analysisCtx bounds cloning and LLM work by the configured wall-clock budget.
+	// Results are posted with the parent context so partial reviews still land.
+	analysisCtx := ctx
+	if d := s.cfg.Review.Budget.MaxDuration; d > 0 {
+		var cancel context.CancelFunc
+		analysisCtx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
 
 	// Step 1: Project Architecture Review
 	var token string
@@ -48,7 +87,7 @@ func (s *ReviewService) ProcessPullRequest(baseUrl string, ctx context.Context,
 	} else if strings.EqualFold(baseUrl, constants.GITEA_URL) {
 		token = s.cfg.VCS.Gitea.Token
 	}
-	repoPath, err := utils.CloneRepoIfNotExists(baseUrl, token, prDetails.Owner, prDetails.Repo)
+	repoPath, err := cloneRepo(analysisCtx, baseUrl, token, prDetails.Owner, prDetails.Repo)
 	if err != nil {
 		return "", err
 	}
@@ -59,17 +98,6 @@ func (s *ReviewService) ProcessPullRequest(baseUrl string, ctx context.Context,
 		log.Printf("Cleaned up repo path %s", repoPath)
 	}()
 
-	archReview, err := s.reviewProjectArchitecture(ctx, repoPath)
-	if err != nil {
-		log.Printf("Architecture review failed: %v", err)
-	} else if archReview != nil && archReview.NeedsComment {
-		comment := utils.FormatArchitectureReviewComment(archReview)
-		err := s.repo.PostGeneralComment(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber, comment)
-		if err != nil {
-			log.Printf("Error posting general comment: %v", err)
-		}
-	}
-
 	commitID, err := s.repo.GetPRCommitID(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber)
 	if err != nil {
 		log.Printf("Warning: could not get PR commit ID: %v", err)
@@ -77,20 +105,38 @@ func (s *ReviewService) ProcessPullRequest(baseUrl string, ctx context.Context,
 		log.Printf("Found PR HEAD commit SHA: %s", commitID)
 	}
 
+	// Review against the PR head so file contents match the diff being reviewed.
+	if err := checkoutHead(analysisCtx, repoPath, prDetails.PRNumber, commitID); err != nil {
+		log.Printf("Warning: could not check out PR head, using the default branch: %v", err)
+	}
+	// Everything the run reports outside line comments is collected on run and
+	// written to the single summary comment when the run ends, however it ends.
+	defer s.postSummaryComment(ctx, prDetails, run)
+	run.cfg = withIgnoredPaths(s.loadRepoConfig(analysisCtx, run, prDetails, repoPath), state.IgnoredPaths)
+	run.styleGuides = loadStyleGuides(analysisCtx, prDetails, repoPath, run.cfg.Review.StyleGuides)
+	run.suppressions = s.loadSuppressions(ctx, prDetails, run.cfg.Review.Feedback)
+
+	if run.cfg.Review.CheckEnabled(constants.CHECK_ARCHITECTURE) {
+		archReview, err := s.reviewProjectArchitecture(analysisCtx, run, repoPath)
+		if err != nil {
+			log.Printf("Architecture review failed: %v", err)
+		} else if archReview != nil && archReview.NeedsComment {
+			run.addNote(utils.FormatArchitectureReviewComment(archReview))
+		}
+	}
+
 	diff, err := s.repo.GetPRDiff(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber)
 	if err != nil {
 		return "", fmt.Errorf("failed to get PR diff: %w", err)
 	}
 	log.Println("Successfully fetched PR diff.")
+	diff = filterDiff(diff, run.cfg.Review.Paths)
 
-	testComment, err := s.CheckForMissingTests(ctx, diff, repoPath)
-	if err == nil && testComment != nil {
-		//var testReviewComments []*models.Comment
+	if !run.cfg.Review.CheckEnabled(constants.CHECK_TESTS) {
+		log.Println("Missing-test check is disabled.")
+	} else if testComment, err := s.CheckForMissingTests(ctx, run.cfg.Review.Tests, diff, repoPath); err == nil && testComment != nil {
 		for _, comment := range testComment.Comments {
-			err := s.repo.PostGeneralComment(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber, comment.Body)
-			if err != nil {
-				log.Printf("Error posting general comment: %v", err)
-			}
+			run.addNote(comment.Body)
 		}
 	}
 
@@ -100,74 +146,263 @@ func (s *ReviewService) ProcessPullRequest(baseUrl string, ctx context.Context,
 	}
 	log.Printf("Parsed diff into %d chunks.", len(chunks))
 
-	for _, chunk := range chunks {
-		comments, err := s.analyzeChunk(ctx, chunk)
+	// coverageErr fails the run once the review is posted.
+	var coverageErr error
+	if len(run.cfg.Review.Coverage.Reports) > 0 && run.cfg.Review.CheckEnabled(constants.CHECK_COVERAGE) {
+		var note string
+		note, coverageErr = checkPatchCoverage(run.cfg.Review.Coverage, chunks, repoPath)
+		if note != "" {
+			run.addNote(note)
+		}
+	}
+	prioritizeChunks(chunks)
+	run.intent = s.loadPRIntent(ctx, prDetails)
+	run.symbols = buildSymbolIndex(run.cfg.Review.SymbolContext, repoPath, chunks)
+	run.search = openCodeSearch(analysisCtx, run.cfg.Review.CodeSearch, prDetails, repoPath)
+	run.injections = scanForInjection(chunks, run.intent)
+	for _, f := range run.injections {
+		log.Printf("Possible prompt injection in %s: %q", f.Source, f.Phrase)
+	}
+
+	var budgetErr error
+	var skipped []*diffparser.DiffChunk
+	reviewedFiles := make(map[string]bool)
+	for i, chunk := range chunks {
+		if err := run.checkBudget(analysisCtx); err != nil {
+			budgetErr, skipped = err, chunks[i:]
+			break
+		}
+		comments, model, err := s.analyzeChunk(analysisCtx, run, chunk)
+		if errors.Is(err, errBudgetExhausted) || analysisCtx.Err() != nil {
+			budgetErr, skipped = err, chunks[i:]
+			break
+		}
 		if err != nil {
 			log.Printf("Error analyzing chunk for file %s: %v", chunk.FilePath, err)
 			continue
 		}
+		reviewedFiles[chunk.FilePath] = true
 
 		for _, llmComment := range comments {
+			normalizeFinding(&llmComment)
+			llmComment.Message = sanitizeModelText(llmComment.Message)
+			if !meetsSeverityThreshold(llmComment.Severity, run.cfg.Review.MinSeverityToPost) {
+				log.Printf("Skipping %s finding below threshold in %s", llmComment.Severity, chunk.FilePath)
+				continue
+			}
+			if p := matchSuppression(run.suppressions, chunk.FilePath, llmComment, run.cfg.Review.Feedback); p != nil {
+				log.Printf("Suppressing %s finding in %s: %d similar comments were rejected", llmComment.Category, chunk.FilePath, p.rejections)
+				run.suppressed++
+				continue
+			}
 			positionInHunk, fileLineNumber, err := findLocationForLineContent(chunk, llmComment.LineContent)
 			if err != nil {
 				log.Printf("Could not find location for line content in file %s: %v", chunk.FilePath, err)
 				continue
 			}
+			if run.cfg.Review.Verifier.Enabled && !s.verifyFinding(analysisCtx, run, chunk, llmComment) {
+				run.discarded++
+				continue
+			}
+			body := formatCommentBody(llmComment)
+			if llmComment.Suggestion != "" {
+				diffLine := strings.Split(chunk.CodeSnippet, "\n")[positionInHunk-1]
+				original := strings.TrimPrefix(diffLine, "+")
+				if err := verifySuggestion(repoPath, chunk.FilePath, fileLineNumber, original, llmComment.Suggestion); err != nil {
+					log.Printf("Dropping suggestion for %s:%d: %v", chunk.FilePath, fileLineNumber, err)
+				} else {
+					body += formatSuggestion(run.suggestionStyle, original, llmComment.Suggestion)
+				}
+			}
 			allComments = append(allComments, &models.Comment{
-				Body:     llmComment.Message,
+				Body:     body,
 				Path:     chunk.FilePath,
 				Position: positionInHunk,
 				Line:     fileLineNumber,
+				Model:    model,
+				Severity: llmComment.Severity,
+				Category: llmComment.Category,
 			})
 		}
 	}
 
+	if budgetErr != nil {
+		log.Printf("Stopping analysis early: %v", budgetErr)
+		run.addNote(formatBudgetNote(budgetErr, unreviewedFiles(skipped, reviewedFiles)))
+	}
+
+	if run.cfg.Review.Summary.Enabled {
+		if summary, err := s.summarizePullRequest(analysisCtx, run, chunks, allComments); err != nil {
+			log.Printf("Could not summarize the pull request: %v", err)
+		} else {
+			run.prSummary = summary
+		}
+	}
+
+	if len(run.injections) > 0 {
+		run.addNote(formatInjectionWarning(run.injections))
+	}
+	var postErr error
 	if len(allComments) > 0 {
 		log.Printf("Submitting a review with %d comments.", len(allComments))
-		err := s.repo.PostReview(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber, allComments, commitID)
-		if err != nil {
-			// reviewErr = err
-			return "", fmt.Errorf("failed to post review: %w", err)
+		if err := s.repo.PostReview(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber, allComments, commitID); err != nil {
+			postErr = fmt.Errorf("failed to post review: %w", err)
+			run.status = "❌ AI Review Failed: the review comments could not be posted."
+		} else {
+			run.status = fmt.Sprintf("✅ AI Review Complete: Submitted %d comments.", len(allComments))
 		}
 	} else {
-		log.Println("No comments to post. Submitting a general comment.")
-		s.repo.PostGeneralComment(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber, "✅ AI Review Complete: No issues found.")
+		log.Println("No comments to post.")
+		run.status = "✅ AI Review Complete: No issues found."
+	}
+	if coverageErr != nil {
+		run.status += fmt.Sprintf("\n\n❌ Coverage check failed: %v.", coverageErr)
+	}
+	if run.cfg.Review.ShowUsage {
+		run.status += fmt.Sprintf("\n\n_%s._", formatUsage(run.usage))
+	}
+	if postErr != nil {
+		s.recordReview(ctx, prDetails, run.result(constants.REVIEW_FAILED, nil))
+		s.logAcceptance(ctx, prDetails, run.cfg.Review.Feedback)
+		return "", errors.Join(postErr, coverageErr)
 	}
 
-	resultMessage := fmt.Sprintf("Review complete. Submitted %d comments.", len(allComments))
+	if run.discarded > 0 {
+		log.Printf("Verifier discarded %d findings.", run.discarded)
+	}
+	if run.suppressed > 0 {
+		log.Printf("Suppressed %d findings developers rejected before.", run.suppressed)
+	}
+	s.recordReview(ctx, prDetails, run.result(constants.REVIEW_SUCCESS, allComments))
+	s.logAcceptance(ctx, prDetails, run.cfg.Review.Feedback)
+	if coverageErr != nil {
+		return "", coverageErr
+	}
+
+	resultMessage := fmt.Sprintf("Review complete. Submitted %d comments. %s.", len(allComments), formatUsage(run.usage))
 	log.Println(resultMessage)
 	return resultMessage, nil
 }
 
-func (s *ReviewService) analyzeChunk(ctx context.Context, chunk *diffparser.DiffChunk) ([]models.ReviewComment, error) {
-	prompt, err := preparePrompt(s.cfg.ReviewPrompt, chunk.FilePath, chunk.CodeSnippet)
+// analyzeChunk reviews a single diff hunk and returns the LLM's comments together
+// with the name of the model that produced them.
+func (s *ReviewService) analyzeChunk(ctx context.Context, run *reviewRun, chunk *diffparser.DiffChunk) ([]models.ReviewComment, string, error) {
+	lp := run.cfg.PromptFor(chunk.FilePath)
+	boundary, err := newBoundary()
 	if err != nil {
-		return nil, fmt.Errorf("failed to prepare prompt: %w", err)
+		return nil, "", err
 	}
-
-	res, err := genkitGenerate(ctx, s.g, ai.WithModelName(s.cfg.LLM.ModelName), ai.WithPrompt(prompt))
+	req, err := s.renderPrompt(ctx, run, lp.Prompt, reviewInput{
+		FilePath:    chunk.FilePath,
+		CodeSnippet: chunk.CodeSnippet,
+		Rules:       lp.Rules,
+		Guidelines:  run.cfg.Review.Guidelines,
+		Language:    run.cfg.Review.Language,
+		PullRequest: run.intent,
+		Symbols:     symbolsForChunk(run.symbols, chunk, run.cfg.Review.SymbolContext.MaxTokens),
+		Related:     relatedCode(run.search, chunk, run.cfg.Review.CodeSearch, run.cfg.Review.Paths),
+		Boundary:    boundary,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate LLM response: %w", err)
+		return nil, "", err
 	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate LLM response: %w", err)
+	if err := s.addStyleGuides(ctx, run, req); err != nil {
+		log.Printf("Reviewing %s without style guides: %v", chunk.FilePath, err)
 	}
 
-	responseText := res.Text()
-	if responseText == "" {
-		return nil, fmt.Errorf("failed to get text from LLM response")
+	res, model, err := s.generate(ctx, run, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate LLM response: %w", err)
 	}
 
-	sanitizedJSON := sanitizeJSONString(responseText)
+	sanitizedJSON := sanitizeJSONString(res.Text())
 	if sanitizedJSON == "" {
-		return nil, nil
+		return nil, model, nil
 	}
 
 	var comments []models.ReviewComment
 	if err := json.Unmarshal([]byte(sanitizedJSON), &comments); err != nil {
-		return nil, fmt.Errorf("failed to parse LLM JSON response: %w", err)
+		return nil, model, fmt.Errorf("failed to parse LLM JSON response: %w", err)
+	}
+	return comments, model, nil
+}
+
+// reviewInput is the input of the review prompt and its language variants.
+type reviewInput struct {
+	FilePath    string `json:"filePath"`
+	CodeSnippet string `json:"codeSnippet"`
+	Rules       string `json:"rules,omitempty"`
+	Guidelines  string `json:"guidelines,omitempty"`
+	Language    string `json:"language,omitempty"`
+	// PullRequest is nil when the PR has neither a title nor a description.
+	PullRequest *pullRequestInput `json:"pullRequest,omitempty"`
+	// Symbols are declarations from other files used by the hunk's added lines.
+	Symbols []symbolContext `json:"symbols,omitempty"`
+	// Related is similar code found by the repository's keyword index.
+	Related []relatedSnippet `json:"related,omitempty"`
+	// Boundary delimits the untrusted content in the rendered prompt.
+	Boundary string `json:"boundary"`
+}
+
+// renderPrompt renders a dotprompt and records its version on the run.
+func (s *ReviewService) renderPrompt(ctx context.Context, run *reviewRun, name string, input any) (*ai.GenerateActionOptions, error) {
+	p, err := s.prompts.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	req, err := p.Render(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	run.usePrompt(p.ID())
+	return req, nil
+}
+
+// generate sends the request to each model of the fallback chain in order until one
+// returns a non-empty response. It also reports which model answered. Token usage
+// of every response, including failed attempts, is charged to the run.
+func (s *ReviewService) generate(ctx context.Context, run *reviewRun, req *ai.GenerateActionOptions) (*ai.ModelResponse, string, error) {
+	return s.generateWith(ctx, run, s.cfg.LLM.Chain(), req)
+}
+
+// generateWith is generate with an explicit model chain. A model named in the
+// prompt's front-matter is tried before the chain.
+func (s *ReviewService) generateWith(ctx context.Context, run *reviewRun, chain []config.LLMModelConfig, req *ai.GenerateActionOptions) (*ai.ModelResponse, string, error) {
+	if req.Model != "" && !slices.ContainsFunc(chain, func(m config.LLMModelConfig) bool { return m.ModelName == req.Model }) {
+		chain = append([]config.LLMModelConfig{{ModelName: req.Model}}, chain...)
+	}
+	var errs []error
+	for _, m := range chain {
+		if err := run.checkBudget(ctx); err != nil {
+			return nil, "", err
+		}
+		run.llmCalls++
+		attempt := *req
+		attempt.Model = m.ModelName
+		res, err := genkitGenerate(ctx, s.g, &attempt, nil, nil)
+		if res != nil {
+			run.recordUsage(m.ModelName, res.Usage)
+		}
+		if err == nil && res.Text() == "" {
+			err = fmt.Errorf("empty response")
+		}
+		if err == nil {
+			return res, m.ModelName, nil
+		}
+		log.Printf("Model %s failed: %v", m.ModelName, err)
+		errs = append(errs, fmt.Errorf("%s: %w", m.ModelName, err))
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// recordReview persists the review outcome. Storage failures never fail the review itself.
+func (s *ReviewService) recordReview(ctx context.Context, prDetails *models.PRDetails, result *models.ReviewResult) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.RecordReview(ctx, prDetails, result); err != nil {
+		log.Printf("Warning: failed to record review for PR #%d: %v", prDetails.PRNumber, err)
 	}
-	return comments, nil
 }
 
 // Helper functions (can remain in this file or be moved to a utility package)
@@ -209,22 +444,3 @@ func findLocationForLineContent(chunk *diffparser.DiffChunk, lineContent string)
 	}
 	return -1, -1, fmt.Errorf("line content not found in diff hunk: '%s'", lineContent)
 }
-
-func preparePrompt(promptTmpl, filePath, codeSnippet string) (string, error) {
-	tmpl, err := template.New("review_prompt").Parse(promptTmpl)
-	if err != nil {
-		return "", err
-	}
-	data := struct {
-		FilePath    string
-		CodeSnippet string
-	}{
-		FilePath:    filePath,
-		CodeSnippet: codeSnippet,
-	}
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", err
-	}
-	return buf.String(), nil
-}
@@ -3,10 +3,16 @@ package service
 import (
 	"context"
 	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
 	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/fakellm"
 	"code-reviewer-bot/internal/models"
 	"code-reviewer-bot/internal/repository"
 
@@ -17,31 +23,89 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
-//var genkitGenerate = genkit.Generate
+// stubBaseFiles serves the base branch files from the given map instead of git.
+func stubBaseFiles(t *testing.T, files map[string]string) {
+	originalRead, originalList := readBaseFile, listBaseFiles
+	readBaseFile = func(ctx context.Context, repoPath, baseBranch, path string) ([]byte, bool, error) {
+		content, ok := files[path]
+		return []byte(content), ok, nil
+	}
+	listBaseFiles = func(ctx context.Context, repoPath, baseBranch string) ([]string, error) {
+		return slices.Sorted(maps.Keys(files)), nil
+	}
+	t.Cleanup(func() { readBaseFile, listBaseFiles = originalRead, originalList })
+}
+
+// stubCloneRepo replaces the git clone with a temporary directory holding a layered
+// project and the given files, so ProcessPullRequest runs without network access.
+func stubCloneRepo(t *testing.T, files map[string]string) {
+	originalClone, originalCheckout := cloneRepo, checkoutHead
+	cloneRepo = func(ctx context.Context, baseURL, token, owner, repo string) (string, error) {
+		repoPath := t.TempDir()
+		for _, dir := range []string{"handlers", "service", "models", "config"} {
+			assert.NoError(t, os.Mkdir(filepath.Join(repoPath, dir), 0o755))
+		}
+		for name, content := range files {
+			assert.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644))
+		}
+		return repoPath, nil
+	}
+	checkoutHead = func(ctx context.Context, repoPath string, prNumber int, commitID string) error { return nil }
+	stubBaseFiles(t, files)
+	t.Cleanup(func() { cloneRepo, checkoutHead = originalClone, originalCheckout })
+}
+
+// stubBoundary makes the untrusted-content delimiter predictable, so that rendered
+// prompts match the recorded fixtures.
+func stubBoundary(t *testing.T) {
+	original := newBoundary
+	newBoundary = func() (string, error) { return "untrusted-test", nil }
+	t.Cleanup(func() { newBoundary = original })
+}
+
+// testPromptDir holds the prompt files shipped with the bot.
+const testPromptDir = "../../config/prompts"
+
+// newTestGenkit returns a Genkit instance with the bot's prompt files registered.
+func newTestGenkit(t *testing.T) *genkit.Genkit {
+	t.Helper()
+	g, err := genkit.Init(context.Background(), genkit.WithPromptDir(testPromptDir))
+	assert.NoError(t, err)
+	return g
+}
 
 func TestProcessPullRequest(t *testing.T) {
+	stubCloneRepo(t, nil)
+
 	// Common setup for all ProcessPullRequest tests
 	ctx := context.Background()
 	prDetails := &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1}
 	cfg := &config.Config{
-		LLM:          config.LLMConfig{Provider: "test-provider", ModelName: "test-model"},
-		ReviewPrompt: `{{.CodeSnippet}}`,
+		LLM:       config.LLMConfig{Provider: "test-provider", ModelName: "test-model"},
+		PromptDir: testPromptDir,
 	}
-	var g *genkit.Genkit
+	g := newTestGenkit(t)
 
 	t.Run("Success - posts comments when AI finds issues", func(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockRepo := repository.NewMockVcsRepository(ctrl)
-		reviewService := NewReviewService(mockRepo, g, cfg)
+		reviewService := NewReviewService(mockRepo, nil, g, cfg)
 
 		mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
-		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("diff --git a/main.go b/main.go\n@@ -1,0 +1,1 @@\n+ some change", nil)
+		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,1 @@\n+ some change", nil)
+		mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).Return(&models.PRDetails{Title: "Add a change"}, nil)
 		mockRepo.EXPECT().PostReview(gomock.Any(), "test", "repo", 1, gomock.Any(), "commit123").Return(nil)
+		mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), nil)
+		mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, owner, repo string, prNumber int, body string) error {
+				assert.Equal(t, constants.SUMMARY_COMMENT_MARKER+"\n✅ AI Review Complete: Submitted 1 comments.\n", body)
+				return nil
+			})
 
 		originalGenerate := genkitGenerate
-		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, options ...ai.GenerateOption) (*ai.ModelResponse, error) {
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
 			return &ai.ModelResponse{
 				Message: &ai.Message{
 					Content: []*ai.Part{
@@ -64,14 +128,20 @@ func TestProcessPullRequest(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockRepo := repository.NewMockVcsRepository(ctrl)
-		reviewService := NewReviewService(mockRepo, g, cfg)
+		reviewService := NewReviewService(mockRepo, nil, g, cfg)
 
 		mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
-		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("diff --git a/main.go b/main.go\n@@ -1,0 +1,1 @@\n+ some change", nil)
-		mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,1 @@\n+ some change", nil)
+		mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).Return(&models.PRDetails{Title: "Add a change"}, nil)
+		mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(5), nil)
+		mockRepo.EXPECT().UpdateGeneralComment(gomock.Any(), "test", "repo", int64(5), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, owner, repo string, commentID int64, body string) error {
+				assert.Contains(t, body, "✅ AI Review Complete: No issues found.")
+				return nil
+			})
 
 		originalGenerate := genkitGenerate
-		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, options ...ai.GenerateOption) (*ai.ModelResponse, error) {
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
 			return &ai.ModelResponse{
 				Message: &ai.Message{
 					Content: []*ai.Part{
@@ -87,12 +157,139 @@ func TestProcessPullRequest(t *testing.T) {
 		mock.AssertExpectationsForObjects(t)
 	})
 
+	t.Run("Failure - fails the review when patch coverage is below the minimum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		report := filepath.Join(t.TempDir(), "lcov.info")
+		assert.NoError(t, os.WriteFile(report, []byte("SF:main.go\nDA:1,0\nend_of_record\n"), 0o644))
+		coverageCfg := *cfg
+		coverageCfg.Review.Coverage = config.CoverageConfig{Reports: []string{report}, MinPatchCoverage: 50}
+		reviewService := NewReviewService(mockRepo, nil, g, &coverageCfg)
+
+		mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
+		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,1 @@\n+ some change", nil)
+		mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).Return(&models.PRDetails{Title: "Add a change"}, nil)
+		mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), nil)
+		mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, owner, repo string, prNumber int, body string) error {
+				assert.Contains(t, body, "| `main.go` | 0.0% (0/1) | 1 |")
+				assert.Contains(t, body, "❌ Coverage check failed: patch coverage is below the required minimum (0.0% < 50%).")
+				return nil
+			})
+
+		originalGenerate := genkitGenerate
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+		}
+		defer func() { genkitGenerate = originalGenerate }()
+
+		_, err := reviewService.ProcessPullRequest("", ctx, prDetails)
+		assert.ErrorIs(t, err, errCoverageBelowThreshold)
+	})
+
+	t.Run("Failure - keeps the coverage failure when the review cannot be posted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		report := filepath.Join(t.TempDir(), "lcov.info")
+		assert.NoError(t, os.WriteFile(report, []byte("SF:main.go\nDA:1,0\nend_of_record\n"), 0o644))
+		coverageCfg := *cfg
+		coverageCfg.Review.Coverage = config.CoverageConfig{Reports: []string{report}, MinPatchCoverage: 50}
+		reviewService := NewReviewService(mockRepo, nil, g, &coverageCfg)
+
+		mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
+		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,1 @@\n+ some change", nil)
+		mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).Return(&models.PRDetails{Title: "Add a change"}, nil)
+		mockRepo.EXPECT().PostReview(gomock.Any(), "test", "repo", 1, gomock.Any(), "commit123").Return(errors.New("422 Unprocessable Entity"))
+		mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), nil)
+		mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, owner, repo string, prNumber int, body string) error {
+				assert.Contains(t, body, "❌ AI Review Failed: the review comments could not be posted.")
+				assert.Contains(t, body, "❌ Coverage check failed: patch coverage is below the required minimum (0.0% < 50%).")
+				return nil
+			})
+
+		originalGenerate := genkitGenerate
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[{"line_content": "+ some change", "message": "A valid comment"}]`)}}}, nil
+		}
+		defer func() { genkitGenerate = originalGenerate }()
+
+		_, err := reviewService.ProcessPullRequest("", ctx, prDetails)
+		assert.ErrorContains(t, err, "failed to post review")
+		assert.ErrorIs(t, err, errCoverageBelowThreshold)
+	})
+
+	t.Run("Failure - a repository's show_usage reports usage when the review cannot be posted", func(t *testing.T) {
+		stubBaseFiles(t, map[string]string{".ai-review.yaml": "show_usage: true\n"})
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		reviewService := NewReviewService(mockRepo, nil, g, cfg)
+
+		mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
+		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,1 @@\n+ some change", nil)
+		mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).Return(&models.PRDetails{Title: "Add a change"}, nil)
+		mockRepo.EXPECT().PostReview(gomock.Any(), "test", "repo", 1, gomock.Any(), "commit123").Return(errors.New("422 Unprocessable Entity"))
+		mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), nil)
+		mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, owner, repo string, prNumber int, body string) error {
+				assert.Contains(t, body, "❌ AI Review Failed: the review comments could not be posted.")
+				assert.Contains(t, body, "_Token usage: 10 input / 5 output")
+				return nil
+			})
+
+		originalGenerate := genkitGenerate
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			return &ai.ModelResponse{
+				Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[{"line_content": "+ some change", "message": "A valid comment"}]`)}},
+				Usage:   &ai.GenerationUsage{InputTokens: 10, OutputTokens: 5},
+			}, nil
+		}
+		defer func() { genkitGenerate = originalGenerate }()
+
+		_, err := reviewService.ProcessPullRequest("", ctx, prDetails)
+		assert.ErrorContains(t, err, "failed to post review")
+	})
+
+	t.Run("Success - the coverage check can be disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		report := filepath.Join(t.TempDir(), "lcov.info")
+		assert.NoError(t, os.WriteFile(report, []byte("SF:main.go\nDA:1,0\nend_of_record\n"), 0o644))
+		coverageCfg := *cfg
+		coverageCfg.Review.Coverage = config.CoverageConfig{Reports: []string{report}, MinPatchCoverage: 50}
+		coverageCfg.Review.DisabledChecks = []string{constants.CHECK_COVERAGE}
+		reviewService := NewReviewService(mockRepo, nil, g, &coverageCfg)
+
+		mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
+		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,1 @@\n+ some change", nil)
+		mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).Return(&models.PRDetails{Title: "Add a change"}, nil)
+		mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), nil)
+		mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, owner, repo string, prNumber int, body string) error {
+				assert.NotContains(t, body, "Patch Coverage")
+				return nil
+			})
+
+		originalGenerate := genkitGenerate
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+		}
+		defer func() { genkitGenerate = originalGenerate }()
+
+		_, err := reviewService.ProcessPullRequest("", ctx, prDetails)
+		assert.NoError(t, err)
+	})
+
 	t.Run("Failure - returns error if getting PR diff fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockRepo := repository.NewMockVcsRepository(ctrl)
 
-		reviewService := NewReviewService(mockRepo, g, cfg)
+		reviewService := NewReviewService(mockRepo, nil, g, cfg)
 
 		mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
 		mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return("", errors.New("network error"))
@@ -104,19 +301,67 @@ func TestProcessPullRequest(t *testing.T) {
 	})
 }
 
+// TestProcessPullRequest_EndToEnd runs the whole pipeline against the fake LLM
+// plugin, so prompts are rendered for real and answered from recorded fixtures.
+func TestProcessPullRequest_EndToEnd(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := config.LoadConfig("testdata/config.yaml")
+	assert.NoError(t, err)
+
+	g, err := genkit.Init(ctx, genkit.WithPlugins(fakellm.New(cfg)), genkit.WithPromptDir(cfg.PromptDir))
+	assert.NoError(t, err)
+
+	stubCloneRepo(t, map[string]string{
+		"main.go": "package main\n\nfunc main() {\n\tfmt.Println(\"App Secret:\", ApPSecReT)\n}\n",
+	})
+	stubBoundary(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := repository.NewMockVcsRepository(ctrl)
+	reviewService := NewReviewService(mockRepo, nil, g, cfg)
+
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,4 @@\n package main\n \n func main() {\n+\tfmt.Println(\"App Secret:\", ApPSecReT)\n"
+	mockRepo.EXPECT().GetPRCommitID(gomock.Any(), "test", "repo", 1).Return("commit123", nil)
+	mockRepo.EXPECT().GetPRDiff(gomock.Any(), "test", "repo", 1).Return(diff, nil)
+	mockRepo.EXPECT().GetPullRequest(gomock.Any(), "test", "repo", 1).
+		Return(&models.PRDetails{Title: "Print the app secret on startup", Body: "Fixes #4"}, nil)
+	mockRepo.EXPECT().GetIssue(gomock.Any(), "test", "repo", 4).
+		Return(&models.Issue{Number: 4, Title: "Log configuration at startup", Body: "Operators cannot tell which settings were loaded."}, nil)
+	mockRepo.EXPECT().PostReview(gomock.Any(), "test", "repo", 1, gomock.Any(), "commit123").
+		DoAndReturn(func(ctx context.Context, owner, repo string, prNumber int, comments []*models.Comment, commitID string) error {
+			assert.Len(t, comments, 1)
+			assert.Equal(t, "main.go", comments[0].Path)
+			assert.Equal(t, 4, comments[0].Line)
+			assert.Equal(t, "fake/reviewer", comments[0].Model)
+			assert.Contains(t, comments[0].Body, "AppSecret")
+			assert.Equal(t, "critical", comments[0].Severity)
+			assert.Equal(t, "security", comments[0].Category)
+			assert.Contains(t, comments[0].Body, "🔴 **Critical**")
+			assert.Contains(t, comments[0].Body, "```suggestion\n\tlog.Println(\"App secret loaded\")\n```")
+			return nil
+		})
+	mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), nil)
+	mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, gomock.Any()).Return(nil)
+
+	result, err := reviewService.ProcessPullRequest("", ctx, &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1})
+	assert.NoError(t, err)
+	assert.Contains(t, result, "Submitted 1 comments")
+}
+
 func TestAnalyzeChunk(t *testing.T) {
 	cfg := &config.Config{
-		LLM:          config.LLMConfig{ModelName: "test-model"},
-		ReviewPrompt: `{{.CodeSnippet}}`,
+		LLM:       config.LLMConfig{ModelName: "test-model"},
+		PromptDir: testPromptDir,
 	}
 	chunk := &diffparser.DiffChunk{FilePath: "main.go", CodeSnippet: "+ test line"}
-	var g *genkit.Genkit
+	g := newTestGenkit(t)
 
-	reviewService := NewReviewService(nil, g, cfg)
+	reviewService := NewReviewService(nil, nil, g, cfg)
 
 	t.Run("Success - parses valid JSON", func(t *testing.T) {
 		originalGenerate := genkitGenerate
-		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, options ...ai.GenerateOption) (*ai.ModelResponse, error) {
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
 			return &ai.ModelResponse{
 				Message: &ai.Message{
 					Content: []*ai.Part{
@@ -127,15 +372,42 @@ func TestAnalyzeChunk(t *testing.T) {
 		}
 		defer func() { genkitGenerate = originalGenerate }()
 
-		comments, err := reviewService.analyzeChunk(context.Background(), chunk)
+		comments, _, err := reviewService.analyzeChunk(context.Background(), newReviewRun(cfg), chunk)
 		assert.NoError(t, err)
 		assert.Len(t, comments, 1)
 		assert.Equal(t, "A good comment", comments[0].Message)
 	})
 
+	t.Run("Success - renders the language prompt without escaping code", func(t *testing.T) {
+		cfg := &config.Config{
+			LLM:       config.LLMConfig{ModelName: "test-model"},
+			PromptDir: testPromptDir,
+			LanguagePrompts: []config.LanguagePromptConfig{
+				{Name: "go", Match: []string{".go"}, Prompt: "review", Rules: "- Check errors."},
+			},
+		}
+		reviewService := NewReviewService(nil, nil, g, cfg)
+		chunk := &diffparser.DiffChunk{FilePath: "main.go", CodeSnippet: "+if a < b && c {"}
+
+		var rendered string
+		originalGenerate := genkitGenerate
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			rendered = req.Messages[len(req.Messages)-1].Text()
+			return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+		}
+		defer func() { genkitGenerate = originalGenerate }()
+
+		run := newReviewRun(cfg)
+		_, _, err := reviewService.analyzeChunk(context.Background(), run, chunk)
+		assert.NoError(t, err)
+		assert.Contains(t, rendered, "+if a < b && c {")
+		assert.Contains(t, rendered, "Language-specific rules:\n- Check errors.")
+		assert.Regexp(t, `^review@\S+$`, run.promptVersion())
+	})
+
 	t.Run("Failure - returns error on LLM error", func(t *testing.T) {
 		originalGenerate := genkitGenerate
-		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, options ...ai.GenerateOption) (*ai.ModelResponse, error) {
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
 			return &ai.ModelResponse{
 				Message: &ai.Message{
 					Content: []*ai.Part{
@@ -146,12 +418,87 @@ func TestAnalyzeChunk(t *testing.T) {
 		}
 		defer func() { genkitGenerate = originalGenerate }()
 
-		_, err := reviewService.analyzeChunk(context.Background(), chunk)
+		_, _, err := reviewService.analyzeChunk(context.Background(), newReviewRun(cfg), chunk)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "internal server error")
 	})
 }
 
+func TestGenerate_FallbackChain(t *testing.T) {
+	cfg := &config.Config{
+		LLM: config.LLMConfig{
+			ModelName: "primary-model",
+			Fallbacks: []config.LLMModelConfig{
+				{Provider: "openai", ModelName: "fallback-model"},
+			},
+		},
+	}
+	reviewService := NewReviewService(nil, nil, nil, cfg)
+
+	t.Run("Success - falls back when the primary model fails", func(t *testing.T) {
+		calls := 0
+		originalGenerate := genkitGenerate
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("429 rate limited")
+			}
+			return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+		}
+		defer func() { genkitGenerate = originalGenerate }()
+
+		res, model, err := reviewService.generate(context.Background(), newReviewRun(cfg), &ai.GenerateActionOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "fallback-model", model)
+		assert.Equal(t, "[]", res.Text())
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Failure - returns every model's error when the chain is exhausted", func(t *testing.T) {
+		originalGenerate := genkitGenerate
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			return nil, errors.New("service unavailable")
+		}
+		defer func() { genkitGenerate = originalGenerate }()
+
+		_, _, err := reviewService.generate(context.Background(), newReviewRun(cfg), &ai.GenerateActionOptions{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "primary-model")
+		assert.Contains(t, err.Error(), "fallback-model")
+	})
+}
+
+func TestGenerate_RecordsUsage(t *testing.T) {
+	cfg := &config.Config{
+		LLM: config.LLMConfig{
+			ModelName: "priced-model",
+			Pricing: map[string]config.ModelPrice{
+				"priced-model": {InputPerMillion: 1.0, OutputPerMillion: 4.0},
+			},
+		},
+	}
+	reviewService := NewReviewService(nil, nil, nil, cfg)
+
+	originalGenerate := genkitGenerate
+	genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		return &ai.ModelResponse{
+			Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}},
+			Usage:   &ai.GenerationUsage{InputTokens: 500000, OutputTokens: 250000},
+		}, nil
+	}
+	defer func() { genkitGenerate = originalGenerate }()
+
+	run := newReviewRun(cfg)
+	for i := 0; i < 2; i++ {
+		_, _, err := reviewService.generate(context.Background(), run, &ai.GenerateActionOptions{})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1000000, run.usage.InputTokens)
+	assert.Equal(t, 500000, run.usage.OutputTokens)
+	assert.InDelta(t, 3.0, run.usage.Cost, 1e-9)
+}
+
 func TestFindLocationForLineContent(t *testing.T) {
 	chunk := &diffparser.DiffChunk{
 		FilePath:     "main.go",
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+)
+
+var severityBadges = map[string]string{
+	constants.SEVERITY_INFO:     "🔵 **Info**",
+	constants.SEVERITY_MINOR:    "🟡 **Minor**",
+	constants.SEVERITY_MAJOR:    "🟠 **Major**",
+	constants.SEVERITY_CRITICAL: "🔴 **Critical**",
+}
+
+var categories = []string{
+	constants.CATEGORY_BUG,
+	constants.CATEGORY_SECURITY,
+	constants.CATEGORY_PERFORMANCE,
+	constants.CATEGORY_STYLE,
+	constants.CATEGORY_NAMING,
+}
+
+// normalizeFinding lower-cases the LLM's severity and category. A missing or unknown
+// severity is treated as minor; an unknown category is dropped.
+func normalizeFinding(c *models.ReviewComment) {
+	c.Severity = strings.ToLower(strings.TrimSpace(c.Severity))
+	if !slices.Contains(constants.SEVERITIES, c.Severity) {
+		c.Severity = constants.SEVERITY_MINOR
+	}
+	c.Category = strings.ToLower(strings.TrimSpace(c.Category))
+	if !slices.Contains(categories, c.Category) {
+		c.Category = ""
+	}
+}
+
+func severityRank(severity string) int {
+	return slices.Index(constants.SEVERITIES, severity)
+}
+
+// meetsSeverityThreshold reports whether a finding is severe enough to be posted.
+func meetsSeverityThreshold(severity, minSeverity string) bool {
+	if minSeverity == "" {
+		return true
+	}
+	return severityRank(severity) >= severityRank(minSeverity)
+}
+
+// formatCommentBody prefixes the review message with its severity badge and category.
+func formatCommentBody(c models.ReviewComment) string {
+	header := severityBadges[c.Severity]
+	if c.Category != "" {
+		header += fmt.Sprintf(" · `%s`", c.Category)
+	}
+	return fmt.Sprintf("%s\n\n%s", header, c.Message)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"code-reviewer-bot/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeFinding(t *testing.T) {
+	c := models.ReviewComment{Severity: " MAJOR ", Category: "Performance"}
+	normalizeFinding(&c)
+	assert.Equal(t, "major", c.Severity)
+	assert.Equal(t, "performance", c.Category)
+
+	c = models.ReviewComment{Severity: "blocker", Category: "vibes"}
+	normalizeFinding(&c)
+	assert.Equal(t, "minor", c.Severity)
+	assert.Equal(t, "", c.Category)
+}
+
+func TestMeetsSeverityThreshold(t *testing.T) {
+	testCases := []struct {
+		severity, min string
+		expected      bool
+	}{
+		{"info", "", true},
+		{"info", "minor", false},
+		{"minor", "minor", true},
+		{"critical", "major", true},
+		{"major", "critical", false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, meetsSeverityThreshold(tc.severity, tc.min), "%s >= %s", tc.severity, tc.min)
+	}
+}
+
+func TestFormatCommentBody(t *testing.T) {
+	body := formatCommentBody(models.ReviewComment{Message: "Possible nil dereference.", Severity: "major", Category: "bug"})
+	assert.Equal(t, "🟠 **Major** · `bug`\n\nPossible nil dereference.", body)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/utils"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// truncatedMarker ends a text that was cut to fit the prompt.
+const truncatedMarker = "\n[...truncated]"
+
+// styleGuide is a project document passed to the system part of the review prompt.
+type styleGuide struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// styleGuidesInput is the input of the style guides prompt.
+type styleGuidesInput struct {
+	StyleGuides []styleGuide `json:"styleGuides"`
+}
+
+// addStyleGuides renders the run's style guides and puts them in a system message
+// ahead of the review request, so that the project's conventions frame the review
+// instead of being mixed in with the code under review.
+func (s *ReviewService) addStyleGuides(ctx context.Context, run *reviewRun, req *ai.GenerateActionOptions) error {
+	if len(run.styleGuides) == 0 {
+		return nil
+	}
+	system, err := s.renderPrompt(ctx, run, constants.PROMPT_STYLE_GUIDES, styleGuidesInput{StyleGuides: run.styleGuides})
+	if err != nil {
+		return err
+	}
+	var parts []*ai.Part
+	for _, msg := range system.Messages {
+		parts = append(parts, msg.Content...)
+	}
+	req.Messages = append([]*ai.Message{ai.NewSystemMessage(parts...)}, req.Messages...)
+	return nil
+}
+
+// loadStyleGuides reads the configured style guides from the PR's base branch, in
+// the order of the configured paths, and trims them to the token budget. Missing
+// files are skipped; read errors are logged and only drop the affected guides.
+func loadStyleGuides(ctx context.Context, prDetails *models.PRDetails, repoPath string, cfg config.StyleGuidesConfig) []styleGuide {
+	if len(cfg.Paths) == 0 {
+		return nil
+	}
+	files, err := listBaseFiles(ctx, repoPath, prDetails.BaseBranch)
+	if err != nil {
+		log.Printf("Warning: could not list style guides: %v", err)
+		return nil
+	}
+
+	var guides []styleGuide
+	seen := make(map[string]bool)
+	for _, pattern := range cfg.Paths {
+		for _, file := range files {
+			if seen[file] || !utils.MatchPath(pattern, file) {
+				continue
+			}
+			seen[file] = true
+			content, found, err := readBaseFile(ctx, repoPath, prDetails.BaseBranch, file)
+			if err != nil {
+				log.Printf("Warning: could not read style guide %s: %v", file, err)
+				continue
+			}
+			if text := strings.TrimSpace(string(content)); found && text != "" {
+				guides = append(guides, styleGuide{Path: file, Content: text})
+			}
+		}
+	}
+	return trimStyleGuides(guides, cfg.MaxTokens)
+}
+
+// trimStyleGuides keeps guides in order until maxTokens is used up. The guide that
+// crosses the limit is truncated; the ones after it are dropped. 0 means no limit.
+func trimStyleGuides(guides []styleGuide, maxTokens int) []styleGuide {
+	if maxTokens <= 0 {
+		return guides
+	}
+	remaining := maxTokens
+	for i, guide := range guides {
+		tokens := estimateTokens(guide.Path) + estimateTokens(guide.Content)
+		if tokens <= remaining {
+			remaining -= tokens
+			continue
+		}
+		// Keep a partial guide only if a useful part of it fits.
+		if room := (remaining - estimateTokens(guide.Path) - estimateTokens(truncatedMarker)) * 4; room > 0 {
+			guides[i].Content = truncateText(guide.Content, room)
+			i++
+		}
+		for _, dropped := range guides[i:] {
+			log.Printf("Style guide %s dropped: token budget of %d reached", dropped.Path, maxTokens)
+		}
+		return guides[:i]
+	}
+	return guides
+}
+
+// truncateText cuts s to at most maxBytes bytes, without splitting a UTF-8 sequence,
+// and marks the cut.
+func truncateText(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxBytes], "") + truncatedMarker
+}
+
+// estimateTokens approximates the token count of a text at four bytes per token,
+// which is close enough for English prose and code to keep prompts within budget.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
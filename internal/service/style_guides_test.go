@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadStyleGuides(t *testing.T) {
+	stubBaseFiles(t, map[string]string{
+		"CONTRIBUTING.md":          "Use table-driven tests.",
+		"docs/adr/0002-logging.md": "Log with log.Printf.",
+		"docs/adr/0001-errors.md":  "Wrap errors with %w.",
+		"STYLEGUIDE.md":            "  \n",
+		"main.go":                  "package main",
+	})
+	prDetails := &models.PRDetails{BaseBranch: "main"}
+
+	t.Run("Success - reads matching files in configured order", func(t *testing.T) {
+		guides := loadStyleGuides(context.Background(), prDetails, "", config.StyleGuidesConfig{
+			Paths: []string{"docs/adr/*.md", "CONTRIBUTING.md", "STYLEGUIDE.md", "docs/adr/0001-errors.md"},
+		})
+		assert.Equal(t, []styleGuide{
+			{Path: "docs/adr/0001-errors.md", Content: "Wrap errors with %w."},
+			{Path: "docs/adr/0002-logging.md", Content: "Log with log.Printf."},
+			{Path: "CONTRIBUTING.md", Content: "Use table-driven tests."},
+		}, guides)
+	})
+
+	t.Run("Success - nothing configured", func(t *testing.T) {
+		assert.Empty(t, loadStyleGuides(context.Background(), prDetails, "", config.StyleGuidesConfig{}))
+	})
+}
+
+func TestTrimStyleGuides(t *testing.T) {
+	newGuides := func() []styleGuide {
+		return []styleGuide{
+			{Path: "A.md", Content: strings.Repeat("a", 40)},
+			{Path: "B.md", Content: strings.Repeat("b", 400)},
+			{Path: "C.md", Content: "c"},
+		}
+	}
+
+	t.Run("Success - no limit keeps everything", func(t *testing.T) {
+		assert.Equal(t, newGuides(), trimStyleGuides(newGuides(), 0))
+	})
+
+	t.Run("Success - truncates the guide crossing the budget and drops the rest", func(t *testing.T) {
+		guides := trimStyleGuides(newGuides(), 40)
+		assert.Len(t, guides, 2)
+		assert.Equal(t, strings.Repeat("a", 40), guides[0].Content)
+		assert.True(t, strings.HasSuffix(guides[1].Content, truncatedMarker))
+		total := 0
+		for _, g := range guides {
+			total += estimateTokens(g.Path) + estimateTokens(g.Content)
+		}
+		assert.LessOrEqual(t, total, 40)
+	})
+
+	t.Run("Success - drops a guide when nothing useful fits", func(t *testing.T) {
+		guides := trimStyleGuides(newGuides(), 13)
+		assert.Len(t, guides, 1)
+		assert.Equal(t, "A.md", guides[0].Path)
+	})
+}
+
+func TestAnalyzeChunk_StyleGuides(t *testing.T) {
+	g := newTestGenkit(t)
+	cfg := &config.Config{LLM: config.LLMConfig{ModelName: "test-model"}, PromptDir: testPromptDir}
+	reviewService := NewReviewService(nil, nil, g, cfg)
+	chunk := &diffparser.DiffChunk{FilePath: "main.go", CodeSnippet: "+fmt.Println(x)"}
+
+	var messages []*ai.Message
+	originalGenerate := genkitGenerate
+	genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		messages = req.Messages
+		return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+	}
+	defer func() { genkitGenerate = originalGenerate }()
+
+	t.Run("Success - style guides go into the system message", func(t *testing.T) {
+		run := newReviewRun(cfg)
+		run.styleGuides = []styleGuide{{Path: "CONTRIBUTING.md", Content: "Never use fmt.Println."}}
+		_, _, err := reviewService.analyzeChunk(context.Background(), run, chunk)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, ai.RoleSystem, messages[0].Role)
+		assert.Contains(t, messages[0].Text(), "=== CONTRIBUTING.md ===\nNever use fmt.Println.")
+		assert.Equal(t, ai.RoleUser, messages[1].Role)
+		assert.Contains(t, messages[1].Text(), "+fmt.Println(x)")
+	})
+
+	t.Run("Success - no system message without style guides", func(t *testing.T) {
+		_, _, err := reviewService.analyzeChunk(context.Background(), newReviewRun(cfg), chunk)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, ai.RoleUser, messages[0].Role)
+	})
+}
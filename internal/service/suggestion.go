@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code-reviewer-bot/constants"
+)
+
+const (
+	// suggestionStyleGitHub renders a committable ```suggestion block.
+	suggestionStyleGitHub = "github"
+	// suggestionStyleDiff renders a ```diff block for platforms without suggestion support, such as Gitea.
+	suggestionStyleDiff = "diff"
+)
+
+func suggestionStyleFor(baseUrl string) string {
+	if strings.EqualFold(baseUrl, constants.GITEA_URL) {
+		return suggestionStyleDiff
+	}
+	return suggestionStyleGitHub
+}
+
+// verifySuggestion checks that replacing the given line of the checked-out head file
+// with the suggestion applies cleanly and, for Go files, still parses.
+func verifySuggestion(repoPath, path string, line int, original, suggestion string) error {
+	suggestion = strings.TrimRight(suggestion, "\n")
+	if strings.Contains(suggestion, "```") {
+		return fmt.Errorf("suggestion contains a code fence")
+	}
+	if suggestion == original {
+		return fmt.Errorf("suggestion does not change the line")
+	}
+
+	content, err := os.ReadFile(filepath.Join(repoPath, path))
+	if err != nil {
+		return fmt.Errorf("failed to read head file: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return fmt.Errorf("line %d is outside the file (%d lines)", line, len(lines))
+	}
+	if strings.TrimSuffix(lines[line-1], "\r") != original {
+		return fmt.Errorf("line %d of the head file does not match the commented line", line)
+	}
+
+	if filepath.Ext(path) == ".go" {
+		patched := make([]string, 0, len(lines))
+		patched = append(patched, lines[:line-1]...)
+		patched = append(patched, suggestion)
+		patched = append(patched, lines[line:]...)
+		if _, err := parser.ParseFile(token.NewFileSet(), path, strings.Join(patched, "\n"), parser.AllErrors); err != nil {
+			return fmt.Errorf("patched file no longer parses: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatSuggestion renders a verified suggestion to append to a comment body.
+func formatSuggestion(style, original, suggestion string) string {
+	suggestion = strings.TrimRight(suggestion, "\n")
+	if style == suggestionStyleDiff {
+		var b strings.Builder
+		b.WriteString("\n\n**Suggested change:**\n```diff\n")
+		b.WriteString("-" + original + "\n")
+		for _, l := range strings.Split(suggestion, "\n") {
+			b.WriteString("+" + l + "\n")
+		}
+		b.WriteString("```")
+		return b.String()
+	}
+	return fmt.Sprintf("\n\n```suggestion\n%s\n```", suggestion)
+}
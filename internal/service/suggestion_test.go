@@ -0,0 +1,44 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySuggestion(t *testing.T) {
+	repoPath := t.TempDir()
+	src := "package main\n\nfunc main() {\n\tx := compute()\n\tprintln(x)\n}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "main.go"), []byte(src), 0o644))
+
+	testCases := []struct {
+		name        string
+		line        int
+		original    string
+		suggestion  string
+		expectError string
+	}{
+		{"Valid replacement", 4, "\tx := compute()", "\tx, err := compute()\n\tif err != nil {\n\t\treturn\n\t}", ""},
+		{"Line does not match head file", 4, "\ty := compute()", "\ty := 1", "does not match"},
+		{"Line outside file", 42, "\tx := compute()", "\tx := 1", "outside the file"},
+		{"Does not parse", 4, "\tx := compute()", "\tx := compute(", "no longer parses"},
+		{"No-op suggestion", 4, "\tx := compute()", "\tx := compute()", "does not change"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifySuggestion(repoPath, "main.go", tc.line, tc.original, tc.suggestion)
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
+
+func TestFormatSuggestion(t *testing.T) {
+	assert.Equal(t, "\n\n```suggestion\n\tx := 1\n```", formatSuggestion(suggestionStyleGitHub, "\tx := 2", "\tx := 1"))
+	assert.Equal(t, "\n\n**Suggested change:**\n```diff\n-\tx := 2\n+\tx := 1\n```", formatSuggestion(suggestionStyleDiff, "\tx := 2", "\tx := 1"))
+}
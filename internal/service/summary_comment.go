@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+)
+
+// summaryCommentBody joins the run's PR summary, notes and status into the body of
+// the sticky summary comment. It returns "" when the run produced none of them.
+func summaryCommentBody(run *reviewRun) string {
+	var sections []string
+	if run.prSummary != "" {
+		sections = append(sections, run.prSummary)
+	}
+	sections = append(sections, run.notes...)
+	if run.status != "" {
+		sections = append(sections, run.status)
+	}
+	if len(sections) == 0 {
+		return ""
+	}
+	for i, section := range sections {
+		sections[i] = strings.TrimSpace(section)
+	}
+	return constants.SUMMARY_COMMENT_MARKER + "\n" + strings.Join(sections, "\n\n---\n\n") + "\n"
+}
+
+// postSummaryComment writes the run's summary comment. The comment left by an earlier
+// run is edited in place, so a PR keeps a single bot comment however often it is
+// pushed to; a new comment is posted when there is none or it cannot be edited.
+func (s *ReviewService) postSummaryComment(ctx context.Context, prDetails *models.PRDetails, run *reviewRun) {
+	body := summaryCommentBody(run)
+	if body == "" {
+		return
+	}
+	id, err := s.repo.FindCommentByMarker(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber, constants.SUMMARY_COMMENT_MARKER)
+	if err != nil {
+		log.Printf("Warning: could not look up the summary comment: %v", err)
+	}
+	if id != 0 {
+		err := s.repo.UpdateGeneralComment(ctx, prDetails.Owner, prDetails.Repo, id, body)
+		if err == nil {
+			return
+		}
+		log.Printf("Warning: could not update summary comment %d, posting a new one: %v", id, err)
+	}
+	if err := s.repo.PostGeneralComment(ctx, prDetails.Owner, prDetails.Repo, prDetails.PRNumber, body); err != nil {
+		log.Printf("Error posting general comment: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSummaryCommentBody(t *testing.T) {
+	t.Run("Success - joins the sections under the marker", func(t *testing.T) {
+		run := newReviewRun(&config.Config{})
+		run.prSummary = "## Summary\n"
+		run.addNote("### Architecture")
+		run.status = "✅ Done"
+		assert.Equal(t, constants.SUMMARY_COMMENT_MARKER+"\n## Summary\n\n---\n\n### Architecture\n\n---\n\n✅ Done\n", summaryCommentBody(run))
+	})
+
+	t.Run("Success - empty when the run reported nothing", func(t *testing.T) {
+		assert.Empty(t, summaryCommentBody(newReviewRun(&config.Config{})))
+	})
+}
+
+func TestPostSummaryComment(t *testing.T) {
+	ctx := context.Background()
+	prDetails := &models.PRDetails{Owner: "test", Repo: "repo", PRNumber: 1}
+	run := newReviewRun(&config.Config{})
+	run.status = "✅ Done"
+	body := summaryCommentBody(run)
+
+	t.Run("Success - posts a new comment when the old one cannot be edited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(9), nil)
+		mockRepo.EXPECT().UpdateGeneralComment(gomock.Any(), "test", "repo", int64(9), body).Return(errors.New("403 Forbidden"))
+		mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, body).Return(nil)
+
+		NewReviewService(mockRepo, nil, nil, &config.Config{}).postSummaryComment(ctx, prDetails, run)
+	})
+
+	t.Run("Success - posts a new comment when the lookup fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := repository.NewMockVcsRepository(ctrl)
+		mockRepo.EXPECT().FindCommentByMarker(gomock.Any(), "test", "repo", 1, constants.SUMMARY_COMMENT_MARKER).Return(int64(0), errors.New("timeout"))
+		mockRepo.EXPECT().PostGeneralComment(gomock.Any(), "test", "repo", 1, body).Return(nil)
+
+		NewReviewService(mockRepo, nil, nil, &config.Config{}).postSummaryComment(ctx, prDetails, run)
+	})
+}
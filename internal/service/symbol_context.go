@@ -0,0 +1,88 @@
+package service
+
+import (
+	"log"
+	"strings"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/goindex"
+)
+
+// maxSymbolMatches skips identifiers declared more often than this, such as String
+// or Close methods, whose declarations would not tell the model which one is meant.
+const maxSymbolMatches = 3
+
+// symbolContext is a declaration from another file that the hunk refers to.
+type symbolContext struct {
+	File        string `json:"file"`
+	Declaration string `json:"declaration"`
+}
+
+// buildSymbolIndex indexes the Go declarations of the checked-out PR head when symbol
+// context is enabled and the diff touches Go files. It returns nil otherwise or when
+// the repository cannot be indexed.
+func buildSymbolIndex(cfg config.SymbolContextConfig, repoPath string, chunks []*diffparser.DiffChunk) *goindex.Index {
+	if !cfg.Enabled || !hasGoChunk(chunks) {
+		return nil
+	}
+	idx, err := goindex.Build(repoPath)
+	if err != nil {
+		log.Printf("Warning: could not index Go declarations: %v", err)
+		return nil
+	}
+	return idx
+}
+
+func hasGoChunk(chunks []*diffparser.DiffChunk) bool {
+	for _, chunk := range chunks {
+		if strings.HasSuffix(chunk.FilePath, ".go") {
+			return true
+		}
+	}
+	return false
+}
+
+// symbolsForChunk resolves the identifiers used in a Go hunk's added lines to their
+// declarations in other files, in order of first use, until maxTokens is used up.
+// Declarations that do not fit are skipped so that smaller ones after them can still
+// be added. 0 means no limit.
+func symbolsForChunk(idx *goindex.Index, chunk *diffparser.DiffChunk, maxTokens int) []symbolContext {
+	if idx == nil || !strings.HasSuffix(chunk.FilePath, ".go") {
+		return nil
+	}
+	var symbols []symbolContext
+	seen := make(map[string]bool)
+	used := 0
+	for _, ref := range goindex.Identifiers(addedLines(chunk.CodeSnippet)) {
+		matches := idx.Lookup(ref)
+		if len(matches) > maxSymbolMatches {
+			continue
+		}
+		for _, sym := range matches {
+			if sym.File == chunk.FilePath || seen[sym.Decl] {
+				continue
+			}
+			tokens := estimateTokens(sym.File) + estimateTokens(sym.Decl)
+			if maxTokens > 0 && used+tokens > maxTokens {
+				continue
+			}
+			seen[sym.Decl] = true
+			used += tokens
+			symbols = append(symbols, symbolContext{File: sym.File, Declaration: sym.Decl})
+		}
+	}
+	return symbols
+}
+
+// addedLines returns the lines a hunk adds, without their '+' prefix.
+func addedLines(snippet string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(snippet, "\n") {
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+			b.WriteString(line[1:])
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
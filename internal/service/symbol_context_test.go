@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/goindex"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSymbolIndex(t *testing.T) *goindex.Index {
+	t.Helper()
+	repoPath := t.TempDir()
+	files := map[string]string{
+		"store.go": "package store\n\n// Save writes a review.\nfunc Save(id int) error { return nil }\n\n" +
+			"type Review struct {\n\tID int\n}\n\n" +
+			"func (Review) String() string { return \"\" }\n",
+		"a.go":    "package store\n\ntype A struct{}\n\nfunc (A) String() string { return \"\" }\n",
+		"b.go":    "package store\n\ntype B struct{}\n\nfunc (B) String() string { return \"\" }\n",
+		"c.go":    "package store\n\ntype C struct{}\n\nfunc (C) String() string { return \"\" }\n",
+		"main.go": "package store\n\nfunc helper() {}\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644))
+	}
+	idx := buildSymbolIndex(config.SymbolContextConfig{Enabled: true}, repoPath, []*diffparser.DiffChunk{{FilePath: "main.go"}})
+	assert.NotNil(t, idx)
+	return idx
+}
+
+func TestBuildSymbolIndex(t *testing.T) {
+	chunks := []*diffparser.DiffChunk{{FilePath: "README.md"}}
+
+	t.Run("Success - skipped without Go changes", func(t *testing.T) {
+		assert.Nil(t, buildSymbolIndex(config.SymbolContextConfig{Enabled: true}, t.TempDir(), chunks))
+	})
+
+	t.Run("Success - skipped when disabled", func(t *testing.T) {
+		assert.Nil(t, buildSymbolIndex(config.SymbolContextConfig{}, t.TempDir(), []*diffparser.DiffChunk{{FilePath: "main.go"}}))
+	})
+}
+
+func TestSymbolsForChunk(t *testing.T) {
+	idx := newSymbolIndex(t)
+	chunk := &diffparser.DiffChunk{
+		FilePath:    "main.go",
+		CodeSnippet: " func run() {\n-\told()\n+\tr := Review{}\n+\t_ = Save(r.ID)\n+\thelper()\n+\tprintln(r.String())\n }",
+	}
+
+	t.Run("Success - resolves identifiers from added lines in order", func(t *testing.T) {
+		symbols := symbolsForChunk(idx, chunk, 0)
+		assert.Equal(t, []symbolContext{
+			{File: "store.go", Declaration: "type Review struct {\n\tID int\n}"},
+			{File: "store.go", Declaration: "// Save writes a review.\nfunc Save(id int) error"},
+		}, symbols)
+	})
+
+	t.Run("Success - skips declarations beyond the budget", func(t *testing.T) {
+		symbols := symbolsForChunk(idx, chunk, 12)
+		assert.Equal(t, []symbolContext{{File: "store.go", Declaration: "type Review struct {\n\tID int\n}"}}, symbols)
+	})
+
+	t.Run("Success - no context for other languages", func(t *testing.T) {
+		assert.Nil(t, symbolsForChunk(idx, &diffparser.DiffChunk{FilePath: "main.py", CodeSnippet: "+Save(1)"}, 0))
+	})
+}
+
+func TestAnalyzeChunk_SymbolContext(t *testing.T) {
+	cfg := &config.Config{
+		LLM:       config.LLMConfig{ModelName: "test-model"},
+		PromptDir: testPromptDir,
+		Review:    config.ReviewConfig{SymbolContext: config.SymbolContextConfig{Enabled: true}},
+	}
+	reviewService := NewReviewService(nil, nil, newTestGenkit(t), cfg)
+
+	var rendered string
+	originalGenerate := genkitGenerate
+	genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		rendered = req.Messages[len(req.Messages)-1].Text()
+		return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(`[]`)}}}, nil
+	}
+	defer func() { genkitGenerate = originalGenerate }()
+
+	t.Run("Success - renders the declarations as read-only context", func(t *testing.T) {
+		run := newReviewRun(cfg)
+		run.symbols = newSymbolIndex(t)
+		_, _, err := reviewService.analyzeChunk(context.Background(), run, &diffparser.DiffChunk{FilePath: "main.go", CodeSnippet: "+\t_ = Save(1)"})
+		assert.NoError(t, err)
+		assert.Contains(t, rendered, "read-only context")
+		assert.Contains(t, rendered, "```go\n// store.go\n// Save writes a review.\nfunc Save(id int) error\n")
+	})
+}
@@ -1,62 +1,95 @@
 package service
 
 import (
+	"cmp"
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
 	"code-reviewer-bot/internal/models"
+	"code-reviewer-bot/internal/testcheck"
 	"context"
 	"fmt"
+	"log"
+	"maps"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strings"
+	"slices"
 )
 
-var functionPatterns = map[string]*regexp.Regexp{
-	".go":   regexp.MustCompile(`func\s+([A-Z][A-Za-z0-9_]*)\s*\(`),
-	".js":   regexp.MustCompile(`(?:function\s+([A-Za-z_$][A-Za-z0-9_$]*)|const\s+([A-Za-z_$][A-Za-z0-9_$]*)\s*=)`),
-	".ts":   regexp.MustCompile(`(?:function\s+([A-Za-z_$][A-Za-z0-9_$]*)|const\s+([A-Za-z_$][A-Za-z0-9_$]*)\s*=)`),
-	".py":   regexp.MustCompile(`def\s+([A-Za-z_][A-Za-z0-9_]*)\s*\(`),
-	".java": regexp.MustCompile(`public\s+[A-Za-z<>\[\]]+\s+([A-Za-z_][A-Za-z0-9_]*)\s*\(`),
-}
-
-var testSuffixes = map[string]string{
-	".go":   "_test.go",
-	".js":   ".test.js",
-	".ts":   ".test.ts",
-	".py":   "_test.py",
-	".java": "Test.java",
-}
-
-func (s *ReviewService) CheckForMissingTests(ctx context.Context, diff string, repoPath string) (*models.TestReviewResponse, error) {
-	language := detectLanguage(diff)
-	if language == "" {
+// CheckForMissingTests checks every changed source file with the analyzer of its
+// language and reports the public functions it changes that no test refers to,
+// grouped by language and file. tests overrides the test file conventions.
+func (s *ReviewService) CheckForMissingTests(ctx context.Context, tests config.TestsConfig, diff string, repoPath string) (*models.TestReviewResponse, error) {
+	checker := testcheck.New(tests.Patterns)
+	files := changedSources(checker, diffparser.Parse(diff))
+	if len(files) == 0 {
 		return &models.TestReviewResponse{HasMissing: false}, nil
 	}
 
-	functions := extractFunctions(diff, language)
-	if len(functions) == 0 {
-		return &models.TestReviewResponse{HasMissing: false}, nil
+	index := checker.BuildIndex(repoPath)
+	log.Printf("Indexed %d test files for the missing-test check.", index.Files())
+
+	var missing []models.MissingTest
+	for _, file := range slices.SortedFunc(maps.Keys(files), func(a, b string) int {
+		return cmp.Or(cmp.Compare(checker.AnalyzerFor(a).Name(), checker.AnalyzerFor(b).Name()), cmp.Compare(a, b))
+	}) {
+		analyzer := checker.AnalyzerFor(file)
+		src, err := os.ReadFile(filepath.Join(repoPath, file))
+		if err != nil {
+			log.Printf("Skipping %s in the missing-test check: %v", file, err)
+			continue
+		}
+		symbols := analyzer.PublicSymbols(src, files[file])
+		for _, fn := range findMissingTests(index, analyzer, file, symbols, src) {
+			missing = append(missing, models.MissingTest{Language: analyzer.Name(), File: file, Function: fn})
+		}
 	}
-
-	missingFuncs := findMissingTests(functions, repoPath, language, diff)
-	if len(missingFuncs) == 0 {
+	if len(missing) == 0 {
 		return &models.TestReviewResponse{HasMissing: false}, nil
 	}
 
-	comments := generateTestComments(missingFuncs)
+	comments := generateTestComments(missing)
 
 	return &models.TestReviewResponse{
-		MissingTests: missingFuncs,
+		MissingTests: missing,
 		Comments:     comments,
 		HasMissing:   true,
 	}, nil
 }
 
-func generateTestComments(missingFuncs []string) []models.Comment {
+// changedSources returns the new-file lines each changed source file touches. Test
+// files and languages without an analyzer are left out.
+func changedSources(checker *testcheck.Checker, chunks []*diffparser.DiffChunk) map[string]map[int]bool {
+	files := make(map[string]map[int]bool)
+	for _, chunk := range chunks {
+		analyzer := checker.AnalyzerFor(chunk.FilePath)
+		if analyzer == nil || checker.IsTestFile(analyzer, chunk.FilePath) {
+			continue
+		}
+		if files[chunk.FilePath] == nil {
+			files[chunk.FilePath] = make(map[int]bool)
+		}
+		for _, line := range chunk.AddedLineNumbers(true) {
+			files[chunk.FilePath][line] = true
+		}
+	}
+	return files
+}
+
+func generateTestComments(missing []models.MissingTest) []models.Comment {
 	body := "## 🧪 Missing Unit Tests\n\n"
-	body += "The following functions are missing unit tests:\n\n"
+	body += "The following functions are missing unit tests:\n"
 
-	for _, fn := range missingFuncs {
-		body += fmt.Sprintf("- `%s()`\n", fn)
+	var language, file string
+	for _, m := range missing {
+		if m.Language != language {
+			language, file = m.Language, ""
+			body += fmt.Sprintf("\n### %s\n", language)
+		}
+		if m.File != file {
+			file = m.File
+			body += fmt.Sprintf("\n`%s`\n", file)
+		}
+		body += fmt.Sprintf("- `%s()`\n", m.Function)
 	}
 
 	body += "\n**Why unit tests are important:**\n"
@@ -71,100 +104,19 @@ func generateTestComments(missingFuncs []string) []models.Comment {
 	}}
 }
 
-func detectLanguage(diff string) string {
-	for ext := range functionPatterns {
-		if strings.Contains(diff, ext) {
-			return ext
-		}
-	}
-	return ""
-}
-
-func extractFunctions(diff, language string) map[string]bool {
-	pattern, exists := functionPatterns[language]
-	if !exists {
-		return nil
-	}
-
-	var functions []string
-	funcsMap := make(map[string]bool)
-	lines := strings.Split(diff, "\n")
-
-	for _, line := range lines {
-
-		if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++") {
-			continue
-		}
-
-		matches := pattern.FindAllStringSubmatch(line, -1)
-		var funcName string
-		for _, match := range matches {
-			for i := 1; i < len(match); i++ {
-				if match[i] != "" {
-					if !strings.Contains(match[i], "Test") {
-						functions = append(functions, match[i])
-						funcName = match[i]
-						funcsMap[match[i]] = false
-					} else {
-						break
-					}
-				}
-			}
-		}
-		if funcName != "" {
-			if strings.Contains(diff, "Test"+funcName) || strings.Contains(diff, funcName+"Test") {
-				if _, ok := funcsMap[funcName]; ok {
-					funcsMap[funcName] = true
-				}
-			}
-		}
-
-	}
-
-	return funcsMap
-}
-
-func findMissingTests(functions map[string]bool, repoPath, language, diff string) []string {
-	testSuffix, exists := testSuffixes[language]
-	if !exists {
-		return nil
+// findMissingTests returns the symbols of a source file that neither its own inline
+// tests nor an indexed test file that covers it refers to.
+func findMissingTests(index *testcheck.Index, analyzer testcheck.LanguageAnalyzer, file string, symbols []string, src []byte) []string {
+	inline := make(map[string]bool)
+	for _, ref := range testcheck.InlineReferences(analyzer, src) {
+		inline[ref] = true
 	}
 
 	var missing []string
-
-	for fn, ok := range functions {
-		if !ok {
-			hasTest := false
-
-			filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
-				if err != nil || info.IsDir() {
-					return nil
-				}
-
-				// Check  test files
-				if !strings.HasSuffix(path, testSuffix) {
-					return nil
-				}
-
-				content, err := os.ReadFile(path)
-				if err != nil {
-					return nil
-				}
-
-				if strings.Contains(string(content), string("Test"+fn)) {
-					hasTest = true
-					return filepath.SkipDir
-				}
-
-				return nil
-			})
-
-			if !hasTest {
-				missing = append(missing, fn)
-			}
+	for _, fn := range symbols {
+		if !testcheck.IsReferenced(fn, inline) && !index.IsTested(analyzer, file, fn) {
+			missing = append(missing, fn)
 		}
-
 	}
-
 	return missing
 }
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckForMissingTests(t *testing.T) {
+	repoPath := t.TempDir()
+	files := map[string]string{
+		"server.go":            "package main\n\nfunc Serve() {}\n\nfunc Stop() {}\n",
+		"server_test.go":       "package main\n\nfunc TestStop(t *testing.T) {}\n",
+		"web/app.ts":           "export function render() {}\nexport function mount() {}\n",
+		"web/__tests__/app.ts": "import { mount } from '../app'\n\nit('mounts', () => mount())\n",
+		"scripts/build.py":     "def build():\n    pass\n",
+		"docs/README.md":       "# Docs\n",
+	}
+	for path, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(repoPath, filepath.Dir(path)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, path), []byte(content), 0o644))
+	}
+	diff := "diff --git a/web/app.ts b/web/app.ts\n--- a/web/app.ts\n+++ b/web/app.ts\n@@ -0,0 +1,2 @@\n+export function render() {}\n+export function mount() {}\n" +
+		"diff --git a/server.go b/server.go\n--- a/server.go\n+++ b/server.go\n@@ -0,0 +1,5 @@\n+package main\n+\n+func Serve() {}\n+\n+func Stop() {}\n" +
+		"diff --git a/scripts/build.py b/scripts/build.py\n--- a/scripts/build.py\n+++ b/scripts/build.py\n@@ -0,0 +1,2 @@\n+def build():\n+    pass\n" +
+		"diff --git a/docs/README.md b/docs/README.md\n--- a/docs/README.md\n+++ b/docs/README.md\n@@ -0,0 +1,1 @@\n+# Docs\n"
+
+	t.Run("Success - reports untested public functions by language and file", func(t *testing.T) {
+		result, err := NewReviewService(nil, nil, nil, &config.Config{}).CheckForMissingTests(context.Background(), config.TestsConfig{}, diff, repoPath)
+		assert.NoError(t, err)
+		assert.True(t, result.HasMissing)
+		assert.Equal(t, []models.MissingTest{
+			{Language: "Go", File: "server.go", Function: "Serve"},
+			{Language: "Python", File: "scripts/build.py", Function: "build"},
+			{Language: "TypeScript", File: "web/app.ts", Function: "render"},
+		}, result.MissingTests)
+		assert.Contains(t, result.Comments[0].Body, "The following functions are missing unit tests:\n"+
+			"\n### Go\n\n`server.go`\n- `Serve()`\n"+
+			"\n### Python\n\n`scripts/build.py`\n- `build()`\n"+
+			"\n### TypeScript\n\n`web/app.ts`\n- `render()`\n")
+	})
+
+	t.Run("Success - configured test patterns", func(t *testing.T) {
+		tests := config.TestsConfig{Patterns: map[string][]string{"python": {"scripts/checks.py"}}}
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "scripts/checks.py"), []byte("from build import build\n"), 0o644))
+
+		result, err := NewReviewService(nil, nil, nil, &config.Config{}).CheckForMissingTests(context.Background(), tests, diff, repoPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, result.MissingTests, models.MissingTest{Language: "Python", File: "scripts/build.py", Function: "build"})
+	})
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+)
+
+// verificationInput is the input of the verification prompt.
+type verificationInput struct {
+	FilePath    string `json:"filePath"`
+	CodeSnippet string `json:"codeSnippet"`
+	LineContent string `json:"lineContent"`
+	Message     string `json:"message"`
+	Boundary    string `json:"boundary"`
+}
+
+// verdict is the verifier model's judgement of a single finding.
+type verdict struct {
+	Valid      bool    `json:"valid"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// verifyFinding asks the verifier model whether a finding is correct for its hunk and
+// reports whether it should be kept. Findings are kept when verification itself fails,
+// so an unavailable verifier never silences the review.
+func (s *ReviewService) verifyFinding(ctx context.Context, run *reviewRun, chunk *diffparser.DiffChunk, finding models.ReviewComment) bool {
+	boundary, err := newBoundary()
+	if err != nil {
+		log.Printf("Failed to render verification prompt: %v", err)
+		return true
+	}
+	input := verificationInput{
+		FilePath:    chunk.FilePath,
+		CodeSnippet: chunk.CodeSnippet,
+		LineContent: finding.LineContent,
+		Message:     finding.Message,
+		Boundary:    boundary,
+	}
+	req, err := s.renderPrompt(ctx, run, constants.PROMPT_VERIFICATION, input)
+	if err != nil {
+		log.Printf("Failed to render verification prompt: %v", err)
+		return true
+	}
+	res, _, err := s.generateWith(ctx, run, run.cfg.VerifierChain(), req)
+	if err != nil {
+		log.Printf("Verification failed for %s, keeping finding: %v", chunk.FilePath, err)
+		return true
+	}
+	v, err := parseVerdict(res.Text())
+	if err != nil {
+		log.Printf("Failed to parse verifier response for %s, keeping finding: %v", chunk.FilePath, err)
+		return true
+	}
+	if !v.Valid || v.Confidence < run.cfg.Review.Verifier.MinConfidence {
+		log.Printf("Verifier discarded finding in %s (valid=%t, confidence=%.2f): %s", chunk.FilePath, v.Valid, v.Confidence, v.Reason)
+		return false
+	}
+	return true
+}
+
+func parseVerdict(text string) (verdict, error) {
+	var v verdict
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start == -1 || end < start {
+		return v, fmt.Errorf("no JSON object in response")
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/diffparser"
+	"code-reviewer-bot/internal/models"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyFinding(t *testing.T) {
+	cfg := &config.Config{
+		LLM:       config.LLMConfig{ModelName: "reviewer-model"},
+		PromptDir: testPromptDir,
+		Review: config.ReviewConfig{
+			Verifier: config.VerifierConfig{Enabled: true, Provider: "googleai", ModelName: "cheap-model", MinConfidence: 0.7},
+		},
+	}
+	reviewService := NewReviewService(nil, nil, newTestGenkit(t), cfg)
+	chunk := &diffparser.DiffChunk{FilePath: "main.go", CodeSnippet: "@@ -1,1 +1,2 @@\n x := 1\n+if x < 2 && y {"}
+	finding := models.ReviewComment{LineContent: "+if x < 2 && y {", Message: "Missing semicolon"}
+
+	stub := func(t *testing.T, text string, err error) {
+		originalGenerate := genkitGenerate
+		genkitGenerate = func(ctx context.Context, g *genkit.Genkit, req *ai.GenerateActionOptions, mw []ai.ModelMiddleware, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			if err != nil {
+				return nil, err
+			}
+			return &ai.ModelResponse{Message: &ai.Message{Content: []*ai.Part{ai.NewTextPart(text)}}}, nil
+		}
+		t.Cleanup(func() { genkitGenerate = originalGenerate })
+	}
+
+	t.Run("Success - keeps a finding confirmed with high confidence", func(t *testing.T) {
+		stub(t, "```json\n{\"valid\": true, \"confidence\": 0.9, \"reason\": \"real bug\"}\n```", nil)
+		assert.True(t, reviewService.verifyFinding(context.Background(), newReviewRun(cfg), chunk, finding))
+	})
+
+	t.Run("Success - discards a finding classified as invalid", func(t *testing.T) {
+		stub(t, `{"valid": false, "confidence": 0.95, "reason": "Go does not need semicolons"}`, nil)
+		assert.False(t, reviewService.verifyFinding(context.Background(), newReviewRun(cfg), chunk, finding))
+	})
+
+	t.Run("Success - discards a finding below the confidence threshold", func(t *testing.T) {
+		stub(t, `{"valid": true, "confidence": 0.4, "reason": "unsure"}`, nil)
+		assert.False(t, reviewService.verifyFinding(context.Background(), newReviewRun(cfg), chunk, finding))
+	})
+
+	t.Run("Failure - keeps the finding when the verifier is unavailable", func(t *testing.T) {
+		stub(t, "", errors.New("service unavailable"))
+		run := newReviewRun(cfg)
+		assert.True(t, reviewService.verifyFinding(context.Background(), run, chunk, finding))
+		assert.Equal(t, 2, run.llmCalls, "verifier model then the main chain should be tried")
+	})
+
+	t.Run("Failure - keeps the finding when the verdict cannot be parsed", func(t *testing.T) {
+		stub(t, "I think it is fine.", nil)
+		assert.True(t, reviewService.verifyFinding(context.Background(), newReviewRun(cfg), chunk, finding))
+	})
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/constants"
+	"code-reviewer-bot/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// PostgresStore implements the ReviewStore interface using GORM and PostgreSQL.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore connects to PostgreSQL and migrates the review tables.
+func NewPostgresStore(cfg *config.DatabaseConfig) (*PostgresStore, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.AutoMigrate(&models.Project{}, &models.PullRequest{}, &models.ReviewStats{}, &models.PRComment{}, &models.PRState{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// RecordReview stores the pull request, its comments and updates the project's review stats.
+func (p *PostgresStore) RecordReview(ctx context.Context, prDetails *models.PRDetails, result *models.ReviewResult) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		project := models.Project{Name: fmt.Sprintf("%s/%s", prDetails.Owner, prDetails.Repo)}
+		if err := tx.Where(models.Project{Name: project.Name}).FirstOrCreate(&project).Error; err != nil {
+			return fmt.Errorf("failed to load project: %w", err)
+		}
+
+		title := prDetails.Title
+		if title == "" {
+			title = fmt.Sprintf("PR #%d", prDetails.PRNumber)
+		}
+		pr := models.PullRequest{
+			ProjectID:      project.ID,
+			Title:          title,
+			Branch:         prDetails.Branch,
+			Status:         result.Status,
+			ReviewedAt:     time.Now(),
+			PrURL:          prDetails.URL,
+			InputTokens:    result.Usage.InputTokens,
+			OutputTokens:   result.Usage.OutputTokens,
+			Cost:           result.Usage.Cost,
+			DiscardedCount: result.Discarded,
+			PromptVersion:  result.PromptVersion,
+		}
+		if err := tx.Create(&pr).Error; err != nil {
+			return fmt.Errorf("failed to save pull request: %w", err)
+		}
+
+		if len(result.Comments) > 0 {
+			prComments := make([]models.PRComment, 0, len(result.Comments))
+			for _, c := range result.Comments {
+				prComments = append(prComments, models.PRComment{
+					PrID:        pr.ID,
+					FilePath:    c.Path,
+					LineNumber:  c.Line,
+					CommentText: c.Body,
+					CommentType: c.Category,
+					Severity:    c.Severity,
+					ModelName:   c.Model,
+				})
+			}
+			if err := tx.Create(&prComments).Error; err != nil {
+				return fmt.Errorf("failed to save review comments: %w", err)
+			}
+		}
+
+		stats := models.ReviewStats{ProjectID: project.ID}
+		if err := tx.Where(models.ReviewStats{ProjectID: project.ID}).FirstOrCreate(&stats).Error; err != nil {
+			return fmt.Errorf("failed to load review stats: %w", err)
+		}
+		stats.TotalCount++
+		stats.InputTokens += int64(result.Usage.InputTokens)
+		stats.OutputTokens += int64(result.Usage.OutputTokens)
+		stats.TotalCost += result.Usage.Cost
+		if result.Status == constants.REVIEW_SUCCESS {
+			stats.SuccessCount++
+		} else {
+			stats.FailedCount++
+		}
+		if err := tx.Save(&stats).Error; err != nil {
+			return fmt.Errorf("failed to update review stats: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetPRState loads the slash-command state of a pull request.
+func (p *PostgresStore) GetPRState(ctx context.Context, owner, repo string, prNumber int) (*models.PRState, error) {
+	db := p.db.WithContext(ctx)
+	var project models.Project
+	if err := db.Where(models.Project{Name: fmt.Sprintf("%s/%s", owner, repo)}).Limit(1).Find(&project).Error; err != nil {
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+	state := models.PRState{ProjectID: project.ID, PRNumber: prNumber}
+	if project.ID == 0 {
+		return &state, nil
+	}
+	if err := db.Where(models.PRState{ProjectID: project.ID, PRNumber: prNumber}).Limit(1).Find(&state).Error; err != nil {
+		return nil, fmt.Errorf("failed to load pull request state: %w", err)
+	}
+	return &state, nil
+}
+
+// SavePRState creates or replaces the slash-command state of a pull request.
+func (p *PostgresStore) SavePRState(ctx context.Context, owner, repo string, prNumber int, state *models.PRState) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		project := models.Project{Name: fmt.Sprintf("%s/%s", owner, repo)}
+		if err := tx.Where(models.Project{Name: project.Name}).FirstOrCreate(&project).Error; err != nil {
+			return fmt.Errorf("failed to load project: %w", err)
+		}
+		var existing models.PRState
+		if err := tx.Where(models.PRState{ProjectID: project.ID, PRNumber: prNumber}).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to load pull request state: %w", err)
+		}
+		state.ID, state.ProjectID, state.PRNumber = existing.ID, project.ID, prNumber
+		if err := tx.Save(state).Error; err != nil {
+			return fmt.Errorf("failed to save pull request state: %w", err)
+		}
+		return nil
+	})
+}
+
+// RecordFeedback sets the verdict of every stored comment with the same path and
+// text, which covers a finding repeated on later pushes too.
+func (p *PostgresStore) RecordFeedback(ctx context.Context, owner, repo string, feedback []*models.CommentFeedback) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, f := range feedback {
+			err := tx.Model(&models.PRComment{}).
+				Where("pr_id IN (?) AND file_path = ? AND comment_text = ?", projectPullRequests(tx, owner, repo), f.Path, f.Body).
+				Updates(map[string]any{"feedback": f.Verdict, "feedback_reason": f.Reason}).Error
+			if err != nil {
+				return fmt.Errorf("failed to save feedback: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListRejectedComments loads the most recent rejected comments of a repository.
+func (p *PostgresStore) ListRejectedComments(ctx context.Context, owner, repo string, limit int) ([]*models.PRComment, error) {
+	db := p.db.WithContext(ctx)
+	var comments []*models.PRComment
+	err := db.Where("pr_id IN (?) AND feedback = ?", projectPullRequests(db, owner, repo), constants.FEEDBACK_REJECTED).
+		Order("created_at DESC").Limit(limit).Find(&comments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rejected comments: %w", err)
+	}
+	return comments, nil
+}
+
+// FeedbackStats counts comments per category; comments nobody rejected count as accepted.
+func (p *PostgresStore) FeedbackStats(ctx context.Context, owner, repo string) ([]*models.FeedbackStats, error) {
+	db := p.db.WithContext(ctx)
+	var stats []*models.FeedbackStats
+	err := db.Model(&models.PRComment{}).
+		Select("comment_type AS category, COUNT(*) AS posted, SUM(CASE WHEN feedback = ? THEN 1 ELSE 0 END) AS rejected", constants.FEEDBACK_REJECTED).
+		Where("pr_id IN (?)", projectPullRequests(db, owner, repo)).
+		Group("comment_type").Order("comment_type").Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count feedback: %w", err)
+	}
+	return stats, nil
+}
+
+// projectPullRequests is a subquery selecting the IDs of a repository's reviews.
+func projectPullRequests(db *gorm.DB, owner, repo string) *gorm.DB {
+	return db.Model(&models.PullRequest{}).Select("pull_requests.id").
+		Joins("JOIN projects ON projects.id = pull_requests.project_id").
+		Where("projects.name = ?", fmt.Sprintf("%s/%s", owner, repo))
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"code-reviewer-bot/config"
+	"code-reviewer-bot/internal/models"
+)
+
+// ErrNoDatabase is returned when state has to be saved but no database is configured.
+var ErrNoDatabase = errors.New("no database is configured")
+
+// ReviewStore defines the persistence operations for review results.
+//
+//go:generate mockgen -source=store.go -destination=store_mock.go -package=storage
+type ReviewStore interface {
+	RecordReview(ctx context.Context, prDetails *models.PRDetails, result *models.ReviewResult) error
+	// GetPRState returns the slash-command state of a pull request. A pull request
+	// that never received a command has the zero state.
+	GetPRState(ctx context.Context, owner, repo string, prNumber int) (*models.PRState, error)
+	SavePRState(ctx context.Context, owner, repo string, prNumber int, state *models.PRState) error
+	// RecordFeedback stores verdicts on the repository's comments identified by their
+	// path and text. Feedback on comments it never recorded is ignored.
+	RecordFeedback(ctx context.Context, owner, repo string, feedback []*models.CommentFeedback) error
+	// ListRejectedComments returns up to limit rejected comments of the repository,
+	// newest first.
+	ListRejectedComments(ctx context.Context, owner, repo string, limit int) ([]*models.PRComment, error)
+	// FeedbackStats counts the repository's posted and rejected comments per category.
+	FeedbackStats(ctx context.Context, owner, repo string) ([]*models.FeedbackStats, error)
+}
+
+// New returns a PostgreSQL-backed store, or a no-op store when no database host is configured.
+func New(cfg *config.DatabaseConfig) (ReviewStore, error) {
+	if cfg == nil || cfg.Host == "" {
+		return nopStore{}, nil
+	}
+	return NewPostgresStore(cfg)
+}
+
+// nopStore discards everything. It is used when the bot runs without a database.
+type nopStore struct{}
+
+func (nopStore) RecordReview(ctx context.Context, prDetails *models.PRDetails, result *models.ReviewResult) error {
+	return nil
+}
+
+func (nopStore) GetPRState(ctx context.Context, owner, repo string, prNumber int) (*models.PRState, error) {
+	return &models.PRState{PRNumber: prNumber}, nil
+}
+
+func (nopStore) SavePRState(ctx context.Context, owner, repo string, prNumber int, state *models.PRState) error {
+	return ErrNoDatabase
+}
+
+func (nopStore) RecordFeedback(ctx context.Context, owner, repo string, feedback []*models.CommentFeedback) error {
+	return nil
+}
+
+func (nopStore) ListRejectedComments(ctx context.Context, owner, repo string, limit int) ([]*models.PRComment, error) {
+	return nil, nil
+}
+
+func (nopStore) FeedbackStats(ctx context.Context, owner, repo string) ([]*models.FeedbackStats, error) {
+	return nil, nil
+}
@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=store.go -destination=store_mock.go -package=storage
+//
+
+// Package storage is a generated GoMock package.
+package storage
+
+import (
+	models "code-reviewer-bot/internal/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReviewStore is a mock of ReviewStore interface.
+type MockReviewStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockReviewStoreMockRecorder is the mock recorder for MockReviewStore.
+type MockReviewStoreMockRecorder struct {
+	mock *MockReviewStore
+}
+
+// NewMockReviewStore creates a new mock instance.
+func NewMockReviewStore(ctrl *gomock.Controller) *MockReviewStore {
+	mock := &MockReviewStore{ctrl: ctrl}
+	mock.recorder = &MockReviewStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReviewStore) EXPECT() *MockReviewStoreMockRecorder {
+	return m.recorder
+}
+
+// FeedbackStats mocks base method.
+func (m *MockReviewStore) FeedbackStats(ctx context.Context, owner, repo string) ([]*models.FeedbackStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FeedbackStats", ctx, owner, repo)
+	ret0, _ := ret[0].([]*models.FeedbackStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FeedbackStats indicates an expected call of FeedbackStats.
+func (mr *MockReviewStoreMockRecorder) FeedbackStats(ctx, owner, repo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeedbackStats", reflect.TypeOf((*MockReviewStore)(nil).FeedbackStats), ctx, owner, repo)
+}
+
+// GetPRState mocks base method.
+func (m *MockReviewStore) GetPRState(ctx context.Context, owner, repo string, prNumber int) (*models.PRState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPRState", ctx, owner, repo, prNumber)
+	ret0, _ := ret[0].(*models.PRState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPRState indicates an expected call of GetPRState.
+func (mr *MockReviewStoreMockRecorder) GetPRState(ctx, owner, repo, prNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPRState", reflect.TypeOf((*MockReviewStore)(nil).GetPRState), ctx, owner, repo, prNumber)
+}
+
+// ListRejectedComments mocks base method.
+func (m *MockReviewStore) ListRejectedComments(ctx context.Context, owner, repo string, limit int) ([]*models.PRComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRejectedComments", ctx, owner, repo, limit)
+	ret0, _ := ret[0].([]*models.PRComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRejectedComments indicates an expected call of ListRejectedComments.
+func (mr *MockReviewStoreMockRecorder) ListRejectedComments(ctx, owner, repo, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRejectedComments", reflect.TypeOf((*MockReviewStore)(nil).ListRejectedComments), ctx, owner, repo, limit)
+}
+
+// RecordFeedback mocks base method.
+func (m *MockReviewStore) RecordFeedback(ctx context.Context, owner, repo string, feedback []*models.CommentFeedback) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFeedback", ctx, owner, repo, feedback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFeedback indicates an expected call of RecordFeedback.
+func (mr *MockReviewStoreMockRecorder) RecordFeedback(ctx, owner, repo, feedback any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFeedback", reflect.TypeOf((*MockReviewStore)(nil).RecordFeedback), ctx, owner, repo, feedback)
+}
+
+// RecordReview mocks base method.
+func (m *MockReviewStore) RecordReview(ctx context.Context, prDetails *models.PRDetails, result *models.ReviewResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordReview", ctx, prDetails, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordReview indicates an expected call of RecordReview.
+func (mr *MockReviewStoreMockRecorder) RecordReview(ctx, prDetails, result any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordReview", reflect.TypeOf((*MockReviewStore)(nil).RecordReview), ctx, prDetails, result)
+}
+
+// SavePRState mocks base method.
+func (m *MockReviewStore) SavePRState(ctx context.Context, owner, repo string, prNumber int, state *models.PRState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePRState", ctx, owner, repo, prNumber, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePRState indicates an expected call of SavePRState.
+func (mr *MockReviewStoreMockRecorder) SavePRState(ctx, owner, repo, prNumber, state any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePRState", reflect.TypeOf((*MockReviewStore)(nil).SavePRState), ctx, owner, repo, prNumber, state)
+}
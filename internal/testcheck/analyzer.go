@@ -0,0 +1,173 @@
+// Package testcheck finds the public functions a change adds or modifies and tells
+// whether the repository's tests refer to them. Each supported language has a
+// LanguageAnalyzer that knows how it declares public symbols and names its tests.
+package testcheck
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"code-reviewer-bot/internal/utils"
+)
+
+// LanguageAnalyzer knows the declarations and test conventions of one language.
+type LanguageAnalyzer interface {
+	// ID is the lower-case key of the language in the configuration, e.g. "python".
+	ID() string
+	// Name is the language's name in reports, e.g. "Python".
+	Name() string
+	// Extensions lists the file extensions of the language's sources, e.g. ".py".
+	Extensions() []string
+	// TestPatterns are the default globs of test files, e.g. "test_*.py".
+	TestPatterns() []string
+	// PublicSymbols returns the public functions and methods of a source file whose
+	// declaration contains one of the changed lines. Methods are named "Type.Method".
+	PublicSymbols(src []byte, changed map[int]bool) []string
+	// References returns the identifiers a test file refers to.
+	References(src []byte) []string
+}
+
+// inlineTester is implemented by languages whose unit tests may live in the source
+// file they test, such as Rust's #[cfg(test)] modules.
+type inlineTester interface {
+	// InlineReferences returns the identifiers the file's own tests refer to.
+	InlineReferences(src []byte) []string
+}
+
+// packageTester is implemented by languages whose tests live in the directory of
+// the package they test, such as Go. References of their tests only count for the
+// sources of that directory, so that a Close tested in one package does not cover
+// every other Close.
+type packageTester interface {
+	packageTests()
+}
+
+// analyzers are the built-in languages, in the order files are matched against them.
+var analyzers = []LanguageAnalyzer{
+	goAnalyzer{},
+	pythonAnalyzer,
+	javaScriptAnalyzer,
+	typeScriptAnalyzer,
+	javaAnalyzer,
+	rustAnalyzer,
+	cSharpAnalyzer,
+	rubyAnalyzer,
+}
+
+// Languages returns the configuration keys of the built-in languages.
+func Languages() []string {
+	ids := make([]string, 0, len(analyzers))
+	for _, a := range analyzers {
+		ids = append(ids, a.ID())
+	}
+	return ids
+}
+
+// Checker matches files to their language and tells test files from sources.
+type Checker struct {
+	// patterns holds the compiled test file globs of each language, keyed by ID.
+	patterns map[string][]*utils.PathPattern
+}
+
+// New returns a checker whose test file conventions are the built-in ones, except
+// for the languages patterns sets, keyed by language ID.
+func New(patterns map[string][]string) *Checker {
+	c := &Checker{patterns: make(map[string][]*utils.PathPattern)}
+	for _, a := range analyzers {
+		globs := a.TestPatterns()
+		if custom, ok := patterns[a.ID()]; ok {
+			globs = custom
+		}
+		for _, glob := range globs {
+			c.patterns[a.ID()] = append(c.patterns[a.ID()], utils.CompilePath(glob))
+		}
+	}
+	return c
+}
+
+// AnalyzerFor returns the analyzer of a file's language, or nil when the language
+// is not supported.
+func (c *Checker) AnalyzerFor(filePath string) LanguageAnalyzer {
+	ext := path.Ext(filePath)
+	for _, a := range analyzers {
+		if slices.Contains(a.Extensions(), ext) {
+			return a
+		}
+	}
+	return nil
+}
+
+// IsTestFile reports whether a repository-relative path is a test file of the
+// analyzer's language.
+func (c *Checker) IsTestFile(a LanguageAnalyzer, filePath string) bool {
+	if !slices.Contains(a.Extensions(), path.Ext(filePath)) {
+		return false
+	}
+	return slices.ContainsFunc(c.patterns[a.ID()], func(p *utils.PathPattern) bool { return p.Match(filePath) })
+}
+
+// InlineReferences returns the identifiers referred to by tests inside a source
+// file, for languages that keep unit tests next to the code.
+func InlineReferences(a LanguageAnalyzer, src []byte) []string {
+	if t, ok := a.(inlineTester); ok {
+		return t.InlineReferences(src)
+	}
+	return nil
+}
+
+// IsReferenced reports whether a symbol is among the references of a test. Methods
+// count as referenced by their own name, since tests call them on a value.
+func IsReferenced(symbol string, refs map[string]bool) bool {
+	if refs[symbol] {
+		return true
+	}
+	return refs[symbol[strings.LastIndex(symbol, ".")+1:]]
+}
+
+var identifierPattern = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// identifiers returns the distinct identifiers of a source text, and the names of
+// the symbols that test names such as "TestServer_Close", "test_build" or
+// "testRender" are named after.
+func identifiers(src []byte) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range identifierPattern.FindAllString(string(src), -1) {
+		add(id)
+		for _, name := range testedNames(id) {
+			add(name)
+		}
+	}
+	return ids
+}
+
+// testedNames returns what a test name refers to: the name without its "Test" or
+// "test_" prefix, each of its underscore-separated parts, and the same names with a
+// lower-case first letter.
+func testedNames(id string) []string {
+	var rest string
+	switch {
+	case strings.HasPrefix(id, "test_"):
+		rest = id[len("test_"):]
+	case len(id) > 4 && strings.EqualFold(id[:4], "test") && id[4] >= 'A' && id[4] <= 'Z':
+		rest = id[4:]
+	}
+	if rest == "" {
+		return nil
+	}
+	names := append([]string{rest}, strings.Split(rest, "_")...)
+	for _, name := range names {
+		if name != "" {
+			names = append(names, strings.ToLower(name[:1])+name[1:])
+		}
+	}
+	return names
+}
@@ -0,0 +1,61 @@
+package testcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker(t *testing.T) {
+	t.Run("Success - built-in test conventions", func(t *testing.T) {
+		c := New(nil)
+		for path, want := range map[string]bool{
+			"server_test.go":              true,
+			"server.go":                   false,
+			"tests/test_build.py":         true,
+			"pkg/build_test.py":           true,
+			"pkg/build.py":                false,
+			"web/app.spec.ts":             true,
+			"web/__tests__/app.tsx":       true,
+			"web/app.ts":                  false,
+			"src/test/java/AppTest.java":  true,
+			"src/main/java/App.java":      false,
+			"tests/integration.rs":        true,
+			"src/lib.rs":                  false,
+			"App.Tests/ServiceTests.cs":   true,
+			"spec/models/user_spec.rb":    true,
+			"app/models/user.rb":          false,
+			"web/__tests__/fixtures.json": false,
+		} {
+			a := c.AnalyzerFor(path)
+			if a == nil {
+				assert.False(t, want, path)
+				continue
+			}
+			assert.Equal(t, want, c.IsTestFile(a, path), path)
+		}
+	})
+
+	t.Run("Success - configured patterns replace a language's conventions", func(t *testing.T) {
+		c := New(map[string][]string{"python": {"checks/**"}})
+		python := c.AnalyzerFor("build.py")
+		assert.True(t, c.IsTestFile(python, "checks/build.py"))
+		assert.False(t, c.IsTestFile(python, "test_build.py"))
+		assert.True(t, c.IsTestFile(c.AnalyzerFor("a_test.go"), "a_test.go"))
+	})
+
+	t.Run("Failure - unsupported language", func(t *testing.T) {
+		assert.Nil(t, New(nil).AnalyzerFor("main.kt"))
+	})
+}
+
+func TestIsReferenced(t *testing.T) {
+	refs := map[string]bool{}
+	for _, ref := range identifiers([]byte("func TestServer_Close(t *testing.T) {}\ndef test_build_index(): pass\nvoid testRender() {}")) {
+		refs[ref] = true
+	}
+	assert.True(t, IsReferenced("Server.Close", refs))
+	assert.True(t, IsReferenced("build_index", refs))
+	assert.True(t, IsReferenced("App.render", refs))
+	assert.False(t, IsReferenced("Server.Open", refs))
+}
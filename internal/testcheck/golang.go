@@ -0,0 +1,91 @@
+package testcheck
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+
+	"code-reviewer-bot/internal/goindex"
+)
+
+// goAnalyzer parses Go with go/ast. Exported functions and the exported methods of
+// exported types are public.
+type goAnalyzer struct{}
+
+func (goAnalyzer) ID() string             { return "go" }
+func (goAnalyzer) Name() string           { return "Go" }
+func (goAnalyzer) Extensions() []string   { return []string{".go"} }
+func (goAnalyzer) TestPatterns() []string { return []string{"*_test.go"} }
+func (goAnalyzer) packageTests()          {}
+
+func (goAnalyzer) PublicSymbols(src []byte, changed map[int]bool) []string {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var symbols []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !fn.Name.IsExported() {
+			continue
+		}
+		// A method counts when its receiver type is exported, too.
+		name := goindex.FuncName(fn)
+		if !ast.IsExported(name) {
+			continue
+		}
+		start, end := fset.Position(fn.Pos()).Line, fset.Position(fn.End()).Line
+		for line := start; line <= end; line++ {
+			if changed[line] {
+				symbols = append(symbols, name)
+				break
+			}
+		}
+	}
+	slices.Sort(symbols)
+	return slices.Compact(symbols)
+}
+
+// References returns the identifiers a Go test file uses, which covers functions
+// called from table-driven tests, together with the names of its Test functions
+// and t.Run subtests stripped down to what they are named after. Comments do not
+// count. A file that does not parse falls back to its raw identifiers.
+func (goAnalyzer) References(src []byte) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return identifiers(src)
+	}
+	seen := make(map[string]bool)
+	var refs []string
+	add := func(names ...string) {
+		for _, name := range names {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				refs = append(refs, name)
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			add(n.Name)
+			add(testedNames(n.Name)...)
+		case *ast.CallExpr:
+			// t.Run("Serve", ...) and t.Run("TestServer_Close", ...) name what they test.
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Run" || len(n.Args) != 2 {
+				break
+			}
+			if lit, ok := n.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if name, err := strconv.Unquote(lit.Value); err == nil {
+					add(identifiers([]byte(name))...)
+				}
+			}
+		}
+		return true
+	})
+	return refs
+}
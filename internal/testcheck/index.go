@@ -0,0 +1,82 @@
+package testcheck
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// skippedDirs are never searched for tests.
+var skippedDirs = map[string]bool{".git": true, "node_modules": true}
+
+// Index records the identifiers the test files of a repository refer to, so that
+// any number of symbols can be looked up after reading each test file once.
+type Index struct {
+	// refs holds, per scope, the identifiers its test files refer to. The scope is
+	// the language ID, followed by the directory for package-scoped languages.
+	refs map[string]map[string]bool
+	// files counts the test files read.
+	files int
+}
+
+// BuildIndex walks the repository once and indexes the references of every test
+// file, by the conventions of the checker. Unreadable files are skipped.
+func (c *Checker) BuildIndex(repoPath string) *Index {
+	ix := &Index{refs: make(map[string]map[string]bool)}
+	filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return nil
+		}
+		a := c.AnalyzerFor(rel)
+		if a == nil || !c.IsTestFile(a, filepath.ToSlash(rel)) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Skipping test file %s: %v", rel, err)
+			return nil
+		}
+		ix.files++
+		key := refScope(a, filepath.ToSlash(rel))
+		if ix.refs[key] == nil {
+			ix.refs[key] = make(map[string]bool)
+		}
+		for _, ref := range a.References(content) {
+			ix.refs[key][ref] = true
+		}
+		return nil
+	})
+	return ix
+}
+
+// IsTested reports whether a test file of the analyzer's language refers to symbol,
+// declared in the repository-relative file filePath. For package-scoped languages
+// only the tests in the file's directory count.
+func (ix *Index) IsTested(a LanguageAnalyzer, filePath, symbol string) bool {
+	return IsReferenced(symbol, ix.refs[refScope(a, filePath)])
+}
+
+// refScope returns the key of the references that count for a file.
+func refScope(a LanguageAnalyzer, filePath string) string {
+	if _, ok := a.(packageTester); ok {
+		return a.ID() + ":" + path.Dir(filePath)
+	}
+	return a.ID()
+}
+
+// Files returns the number of test files in the index.
+func (ix *Index) Files() int {
+	return ix.files
+}
@@ -0,0 +1,69 @@
+package testcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildIndex(t *testing.T) {
+	repo := t.TempDir()
+	files := map[string]string{
+		"server.go": "package server\n\nfunc Serve() {}\nfunc Parse() {}\nfunc Stop() {}\nfunc Close() {}\n",
+		"server_test.go": `package server
+
+import "testing"
+
+func TestServer(t *testing.T) {
+	// Stop is covered elsewhere.
+	t.Run("Close", func(t *testing.T) {})
+	tests := []struct {
+		in   string
+		want int
+	}{{"a", 1}}
+	for _, tt := range tests {
+		if got := Parse(tt.in); got != tt.want {
+			t.Fail()
+		}
+	}
+}
+`,
+		"tests/test_app.py":                  "from app import build\n",
+		"node_modules/lib/__tests__/x.ts":    "serve()\n",
+		"web/__tests__/app.test.ts":          "import { mount } from '../app'\n",
+		".git/hooks/pre_commit_test.go":      "package hooks\nfunc TestServe() {}\n",
+		"web/__tests__/fixtures/render.json": "{\"render\": true}\n",
+	}
+	for path, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(repo, filepath.Dir(path)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(repo, path), []byte(content), 0o644))
+	}
+
+	c := New(nil)
+	ix := c.BuildIndex(repo)
+	golang, python, typescript := c.AnalyzerFor("x.go"), c.AnalyzerFor("x.py"), c.AnalyzerFor("x.ts")
+
+	t.Run("Success - table-driven calls and subtests count", func(t *testing.T) {
+		assert.True(t, ix.IsTested(golang, "server.go", "Parse"))
+		assert.True(t, ix.IsTested(golang, "server.go", "Server.Close"))
+		assert.True(t, ix.IsTested(python, "app.py", "build"))
+		assert.True(t, ix.IsTested(typescript, "web/app.ts", "App.mount"))
+	})
+
+	t.Run("Failure - comments, skipped directories and other languages do not count", func(t *testing.T) {
+		assert.False(t, ix.IsTested(golang, "server.go", "Stop"))
+		assert.False(t, ix.IsTested(golang, "server.go", "Serve"))
+		assert.False(t, ix.IsTested(typescript, "web/app.ts", "serve"))
+		assert.False(t, ix.IsTested(typescript, "web/app.ts", "render"))
+		assert.False(t, ix.IsTested(python, "app.py", "mount"))
+	})
+
+	t.Run("Failure - Go tests only cover their own package", func(t *testing.T) {
+		assert.False(t, ix.IsTested(golang, "client/client.go", "Client.Close"))
+		assert.False(t, ix.IsTested(golang, "client/client.go", "Parse"))
+	})
+
+	assert.Equal(t, 3, ix.Files())
+}
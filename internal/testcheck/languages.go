@@ -0,0 +1,121 @@
+package testcheck
+
+import (
+	"regexp"
+	"strings"
+)
+
+var braceCloser = regexp.MustCompile(`^\s*}`)
+
+var pythonAnalyzer = &lineAnalyzer{
+	id:           "python",
+	name:         "Python",
+	extensions:   []string{".py"},
+	testPatterns: []string{"test_*.py", "*_test.py"},
+	container:    regexp.MustCompile(`^\s*class\s+(?P<name>\w+)`),
+	function:     regexp.MustCompile(`^\s*(?:async\s+)?def\s+(?P<name>\w+)`),
+	// Names starting with an underscore, dunder methods included, are private by
+	// convention.
+	public: func(line, name string, in *scope) bool { return !strings.HasPrefix(name, "_") },
+}
+
+// jsFunction matches function declarations, functions assigned to a const, and
+// indented class methods.
+var jsFunction = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(?P<name>[A-Za-z_$][\w$]*)` +
+	`|^\s*(?:export\s+)?(?:const|let|var)\s+(?P<name>[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|[A-Za-z_$][\w$]*\s*=>)` +
+	`|^\s+(?:(?:public|private|protected|static|async|readonly|override|get|set)\s+)*(?P<name>#?[A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\([^)]*\)\s*(?::[^={]+)?\{\s*$`)
+
+var jsContainer = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(?P<name>[A-Za-z_$][\w$]*)`)
+
+// jsPublic treats a module's exports as its public API: exported functions, and the
+// methods of exported classes that are neither private nor the constructor.
+func jsPublic(line, name string, in *scope) bool {
+	if in == nil {
+		return strings.HasPrefix(strings.TrimSpace(line), "export ")
+	}
+	if !strings.HasPrefix(strings.TrimSpace(in.line), "export ") {
+		return false
+	}
+	method := strings.TrimSpace(line)
+	return !strings.HasPrefix(method, "private ") && !strings.HasPrefix(method, "protected ") &&
+		!strings.HasPrefix(name, "#") && name != "constructor"
+}
+
+var jsTestPatterns = []string{"*.test.*", "*.spec.*", "**/__tests__/**"}
+
+var javaScriptAnalyzer = &lineAnalyzer{
+	id:           "javascript",
+	name:         "JavaScript",
+	extensions:   []string{".js", ".jsx", ".mjs", ".cjs"},
+	testPatterns: jsTestPatterns,
+	container:    jsContainer,
+	function:     jsFunction,
+	public:       jsPublic,
+	closer:       braceCloser,
+}
+
+var typeScriptAnalyzer = &lineAnalyzer{
+	id:           "typescript",
+	name:         "TypeScript",
+	extensions:   []string{".ts", ".tsx"},
+	testPatterns: jsTestPatterns,
+	container:    jsContainer,
+	function:     jsFunction,
+	public:       jsPublic,
+	closer:       braceCloser,
+}
+
+// hasModifier reports whether a declaration line carries an access modifier.
+func hasModifier(modifier string) func(line, name string, in *scope) bool {
+	pattern := regexp.MustCompile(`(?:^|\s)` + modifier + `\s`)
+	return func(line, name string, in *scope) bool { return pattern.MatchString(line) }
+}
+
+var javaAnalyzer = &lineAnalyzer{
+	id:           "java",
+	name:         "Java",
+	extensions:   []string{".java"},
+	testPatterns: []string{"*Test.java", "*Tests.java", "Test*.java", "*IT.java"},
+	container:    regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|final|abstract|sealed)\s+)*(?:class|interface|enum|record)\s+(?P<name>\w+)`),
+	function: regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|final|abstract|synchronized|native|default)\s+)*(?:<[^>]+>\s+)?` +
+		`(?P<type>[\w.]+(?:<[^;=()]*>)?(?:\[\])*)\s+(?P<name>\w+)\s*\([^;]*$`),
+	public: hasModifier("public"),
+	closer: braceCloser,
+}
+
+var rustAnalyzer = &lineAnalyzer{
+	id:           "rust",
+	name:         "Rust",
+	extensions:   []string{".rs"},
+	testPatterns: []string{"tests/**", "**/tests/**", "*_test.rs"},
+	container:    regexp.MustCompile(`^\s*impl(?:<[^>]*>)?\s+(?:[\w:]+(?:<[^>]*>)?\s+for\s+)?(?P<name>\w+)`),
+	function:     regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:(?:const|async|unsafe|extern(?:\s+"[^"]*")?)\s+)*fn\s+(?P<name>\w+)`),
+	// pub(crate) and other restricted visibilities are not public.
+	public:      func(line, name string, in *scope) bool { return strings.HasPrefix(strings.TrimSpace(line), "pub ") },
+	closer:      braceCloser,
+	inlineTests: regexp.MustCompile(`(?m)^\s*#\[cfg\(test\)\]`),
+}
+
+var cSharpAnalyzer = &lineAnalyzer{
+	id:           "csharp",
+	name:         "C#",
+	extensions:   []string{".cs"},
+	testPatterns: []string{"*Tests.cs", "*Test.cs", "**/*.Tests/**"},
+	container:    regexp.MustCompile(`^\s*(?:(?:public|protected|private|internal|static|sealed|abstract|partial)\s+)*(?:class|struct|interface|record)\s+(?P<name>\w+)`),
+	function: regexp.MustCompile(`^\s*(?:\[[^\]]*\]\s*)*(?:(?:public|private|protected|internal|static|virtual|override|abstract|async|sealed|new|extern|unsafe|partial)\s+)*` +
+		`(?P<type>[\w.]+(?:<[^;=()]*>)?(?:\[\])*\??)\s+(?P<name>\w+)\s*(?:<[^>]*>)?\([^;]*$`),
+	public: hasModifier("public"),
+	closer: braceCloser,
+}
+
+var rubyAnalyzer = &lineAnalyzer{
+	id:             "ruby",
+	name:           "Ruby",
+	extensions:     []string{".rb"},
+	testPatterns:   []string{"*_spec.rb", "*_test.rb", "test_*.rb"},
+	container:      regexp.MustCompile(`^\s*(?:class|module)\s+(?P<name>[A-Z]\w*(?:::\w+)*)`),
+	function:       regexp.MustCompile(`^\s*def\s+(?P<name>(?:self\.)?[A-Za-z_]\w*[?!=]?)`),
+	public:         func(line, name string, in *scope) bool { return true },
+	closer:         regexp.MustCompile(`^\s*end\b`),
+	privateSection: regexp.MustCompile(`^\s*(?:private|protected)\s*$`),
+}
@@ -0,0 +1,216 @@
+package testcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// allLines marks every line of a file as changed.
+func allLines(n int) map[int]bool {
+	changed := make(map[int]bool)
+	for line := 1; line <= n; line++ {
+		changed[line] = true
+	}
+	return changed
+}
+
+func TestGoPublicSymbols(t *testing.T) {
+	src := `package service
+
+type ReviewService struct{}
+
+type run struct{}
+
+// ProcessPullRequest is long.
+func (s *ReviewService) ProcessPullRequest(
+	id int,
+) error {
+	return nil
+}
+
+func (r *run) Close() {}
+
+func Map[T any](xs []T, f func(T) T) []T {
+	for i := range xs {
+		xs[i] = f(xs[i])
+	}
+	return xs
+}
+
+func Unchanged() {}
+
+func helper() {}
+`
+	t.Run("Success - exported functions and methods around the changed lines", func(t *testing.T) {
+		changed := map[int]bool{9: true, 14: true, 18: true, 25: true}
+		assert.Equal(t, []string{"Map", "ReviewService.ProcessPullRequest"}, goAnalyzer{}.PublicSymbols([]byte(src), changed))
+	})
+
+	t.Run("Failure - unparsable source", func(t *testing.T) {
+		assert.Nil(t, goAnalyzer{}.PublicSymbols([]byte("package x\nfunc {"), allLines(2)))
+	})
+}
+
+func TestLinePublicSymbols(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyzer *lineAnalyzer
+		src      string
+		changed  map[int]bool
+		want     []string
+	}{
+		{
+			name:     "Python",
+			analyzer: pythonAnalyzer,
+			src: `def build(target):
+    def step():
+        pass
+    return step
+
+def _helper():
+    pass
+
+class Index:
+    def __init__(self):
+        pass
+
+    async def search(self, query):
+        return []
+`,
+			want: []string{"Index.search", "build"},
+		},
+		{
+			name:     "Python only the changed function",
+			analyzer: pythonAnalyzer,
+			src:      "def build():\n    pass\n\ndef clean(\n    path,\n):\n    pass\n",
+			changed:  map[int]bool{7: true},
+			want:     []string{"clean"},
+		},
+		{
+			name:     "TypeScript",
+			analyzer: typeScriptAnalyzer,
+			src: `export function render(el: Element): void {
+  if (el) {
+    mount(el)
+  }
+}
+
+function mount(el: Element) {}
+
+export const update = async (state: State): Promise<void> => {}
+
+export class App {
+  constructor() {}
+
+  start(port: number): void {
+    listen(port)
+  }
+
+  private stop() {}
+}
+
+class Internal {
+  run() {}
+}
+`,
+			want: []string{"App.start", "render", "update"},
+		},
+		{
+			name:     "Java",
+			analyzer: javaAnalyzer,
+			src: `public class OrderService {
+    public Order place(Order order) {
+        if (order == null) {
+            return validate(order);
+        }
+        return order;
+    }
+
+    private void audit(Order order) {
+    }
+
+    public static List<Order> all()
+    {
+        return List.of();
+    }
+}
+`,
+			want: []string{"OrderService.all", "OrderService.place"},
+		},
+		{
+			name:     "Rust",
+			analyzer: rustAnalyzer,
+			src: `pub fn parse(input: &str) -> Ast {
+    build(input)
+}
+
+fn build(input: &str) -> Ast {}
+
+impl Parser {
+    pub fn new() -> Self {}
+    pub(crate) fn reset(&mut self) {}
+}
+
+#[cfg(test)]
+mod tests {
+    pub fn fixture() {}
+}
+`,
+			want: []string{"Parser.new", "parse"},
+		},
+		{
+			name:     "C#",
+			analyzer: cSharpAnalyzer,
+			src: `public class Cart
+{
+    public async Task<decimal> Total(int id)
+    {
+        return await Sum(id);
+    }
+
+    internal void Clear() {}
+}
+`,
+			want: []string{"Cart.Total"},
+		},
+		{
+			name:     "Ruby",
+			analyzer: rubyAnalyzer,
+			src: `class User
+  def self.find(id)
+    if id
+      new
+    end
+  end
+
+  def name?
+  end
+
+  private
+
+  def secret
+  end
+end
+`,
+			want: []string{"User.find", "User.name?"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("Success - "+tt.name, func(t *testing.T) {
+			changed := tt.changed
+			if changed == nil {
+				changed = allLines(50)
+			}
+			assert.Equal(t, tt.want, tt.analyzer.PublicSymbols([]byte(tt.src), changed))
+		})
+	}
+}
+
+func TestInlineReferences(t *testing.T) {
+	src := []byte("pub fn parse() {}\n\n#[cfg(test)]\nmod tests {\n    #[test]\n    fn parses() { super::parse(); }\n}\n")
+	refs := InlineReferences(rustAnalyzer, src)
+	assert.Contains(t, refs, "parse")
+	assert.NotContains(t, refs, "pub")
+	assert.Nil(t, InlineReferences(pythonAnalyzer, src))
+}
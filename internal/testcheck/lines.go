@@ -0,0 +1,181 @@
+package testcheck
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// keywords are never the name or return type of a declaration; they rule out control
+// statements and calls that look like one, such as "if (ok) {" or "return run(x".
+// "new" only rules out a return type, since constructors are often named new.
+var keywords = map[string]bool{
+	"if": true, "else": true, "for": true, "foreach": true, "while": true, "switch": true,
+	"case": true, "catch": true, "return": true, "throw": true, "do": true,
+	"try": true, "with": true, "using": true, "lock": true, "await": true, "yield": true,
+	"function": true, "typeof": true, "sizeof": true, "nameof": true,
+}
+
+// lineAnalyzer recognizes declarations line by line, for languages without a parser
+// in the standard library. A declaration ends at the next line indented no deeper
+// than it; a closing line such as "}" or "end" at that depth still belongs to it.
+type lineAnalyzer struct {
+	id, name     string
+	extensions   []string
+	testPatterns []string
+	// container matches a class, module or impl block, with its name in the "name"
+	// group. Functions indented below it are its methods, named "Container.method".
+	container *regexp.Regexp
+	// function matches a function or method declaration, with its name in the "name"
+	// group and, optionally, its return type in the "type" group.
+	function *regexp.Regexp
+	// public reports whether a declared function is public; in is the container
+	// the function belongs to, or nil.
+	public func(line, name string, in *scope) bool
+	// closer matches a line that ends the block it is indented at, e.g. "}".
+	closer *regexp.Regexp
+	// privateSection matches a line after which a container's methods are private,
+	// such as Ruby's "private".
+	privateSection *regexp.Regexp
+	// inlineTests matches the start of tests kept inside a source file.
+	inlineTests *regexp.Regexp
+}
+
+// scope is an open container or function.
+type scope struct {
+	name   string
+	line   string
+	indent int
+	// private is set once a container reaches its private section.
+	private bool
+}
+
+func (a *lineAnalyzer) ID() string             { return a.id }
+func (a *lineAnalyzer) Name() string           { return a.name }
+func (a *lineAnalyzer) Extensions() []string   { return a.extensions }
+func (a *lineAnalyzer) TestPatterns() []string { return a.testPatterns }
+
+func (a *lineAnalyzer) PublicSymbols(src []byte, changed map[int]bool) []string {
+	lines := strings.Split(string(src), "\n")
+	var symbols []string
+	var containers []*scope
+	var fn *scope
+	fnPublic, fnStart := false, 0
+	// end closes the open function at line (inclusive), recording it when public
+	// and changed.
+	end := func(line int) {
+		if fn == nil {
+			return
+		}
+		if fnPublic && slices.ContainsFunc(lineRange(fnStart, line), func(n int) bool { return changed[n] }) {
+			symbols = append(symbols, fn.name)
+		}
+		fn = nil
+	}
+
+	for i, text := range lines {
+		n := i + 1
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			continue
+		}
+		if a.inlineTests != nil && a.inlineTests.MatchString(text) {
+			end(n - 1)
+			break
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " \t"))
+		if !strings.ContainsAny(trimmed[:1], ")]{") {
+			closes := a.closer != nil && a.closer.MatchString(text)
+			if fn != nil && indent <= fn.indent {
+				if closes && indent == fn.indent {
+					end(n)
+					continue
+				}
+				end(n - 1)
+			}
+			for len(containers) > 0 && indent <= containers[len(containers)-1].indent && fn == nil {
+				containers = containers[:len(containers)-1]
+				if closes {
+					break
+				}
+			}
+		}
+		if fn != nil {
+			// Anything declared inside a function is part of it.
+			continue
+		}
+
+		var in *scope
+		if len(containers) > 0 {
+			in = containers[len(containers)-1]
+			if a.privateSection != nil && a.privateSection.MatchString(text) {
+				in.private = true
+				continue
+			}
+		}
+		if name := declaredName(a.container, text); name != "" {
+			containers = append(containers, &scope{name: name, line: text, indent: indent})
+			continue
+		}
+		if name := declaredName(a.function, text); name != "" {
+			fn = &scope{name: strings.TrimPrefix(name, "self."), line: text, indent: indent}
+			fnPublic, fnStart = a.public(text, name, in), n
+			if in != nil {
+				fnPublic = fnPublic && !in.private
+				fn.name = in.name + "." + fn.name
+			}
+		}
+	}
+	end(len(lines))
+	slices.Sort(symbols)
+	return slices.Compact(symbols)
+}
+
+func (a *lineAnalyzer) References(src []byte) []string {
+	return identifiers(src)
+}
+
+func (a *lineAnalyzer) InlineReferences(src []byte) []string {
+	if a.inlineTests == nil {
+		return nil
+	}
+	loc := a.inlineTests.FindIndex(src)
+	if loc == nil {
+		return nil
+	}
+	return identifiers(src[loc[0]:])
+}
+
+// declaredName returns the first non-empty "name" group of a declaration pattern,
+// or "" when the line does not declare anything.
+func declaredName(pattern *regexp.Regexp, line string) string {
+	if pattern == nil {
+		return ""
+	}
+	m := pattern.FindStringSubmatch(line)
+	if m == nil {
+		return ""
+	}
+	var name string
+	for i, group := range pattern.SubexpNames() {
+		if group == "name" && m[i] != "" {
+			name = m[i]
+			break
+		}
+	}
+	if keywords[name] {
+		return ""
+	}
+	if i := pattern.SubexpIndex("type"); i >= 0 && (keywords[m[i]] || m[i] == "new") {
+		return ""
+	}
+	return name
+}
+
+func lineRange(from, to int) []int {
+	var lines []int
+	for n := from; n <= to; n++ {
+		lines = append(lines, n)
+	}
+	return lines
+}
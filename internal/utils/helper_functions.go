@@ -2,29 +2,96 @@ package utils
 
 import (
 	"code-reviewer-bot/internal/models"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"path"
+	"regexp"
 	"strings"
 )
 
-func CloneRepoIfNotExists(baseURL, token, owner, repo string) (string, error) {
-	localPath := fmt.Sprintf("./repos/%s_%s", owner, repo)
-	if _, err := os.Stat(localPath); os.IsNotExist(err) {
-		cloneURL := fmt.Sprintf("https://%s@%s/%s/%s.git", token, baseURL, owner, repo)
-		fmt.Println("Cloning:", cloneURL)
-		cmd := exec.Command("git", "clone", cloneURL, localPath)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			log.Printf("Error cloning repository: %v , %v ", err, cmd)
-			return "", err
-		}
+// CloneRepo clones a repository into a new temporary directory and returns its
+// path. Each call gets its own clone, so concurrent reviews of one repository do not
+// check out or remove each other's working tree. The caller removes the clone.
+func CloneRepo(ctx context.Context, baseURL, token, owner, repo string) (string, error) {
+	localPath, err := os.MkdirTemp("", fmt.Sprintf("%s_%s-", owner, repo))
+	if err != nil {
+		return "", err
+	}
+	cloneURL := fmt.Sprintf("https://%s@%s/%s/%s.git", token, baseURL, owner, repo)
+	log.Printf("Cloning %s/%s/%s into %s", baseURL, owner, repo, localPath)
+	cmd := exec.CommandContext(ctx, "git", "clone", cloneURL, localPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(localPath)
+		log.Printf("Error cloning repository: %v", err)
+		return "", err
 	}
 	return localPath, nil
 }
 
+// CheckoutPullRequestHead fetches the pull request's head ref into the clone and checks
+// out the given commit (or the fetched head when commitID is empty) in detached mode.
+func CheckoutPullRequestHead(ctx context.Context, repoPath string, prNumber int, commitID string) error {
+	fetch := exec.CommandContext(ctx, "git", "-C", repoPath, "fetch", "--quiet", "origin", fmt.Sprintf("pull/%d/head", prNumber))
+	if out, err := fetch.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fetch of PR #%d head failed: %w: %s", prNumber, err, strings.TrimSpace(string(out)))
+	}
+	ref := commitID
+	if ref == "" {
+		ref = "FETCH_HEAD"
+	}
+	checkout := exec.CommandContext(ctx, "git", "-C", repoPath, "checkout", "--quiet", "--detach", ref)
+	if out, err := checkout.CombinedOutput(); err != nil {
+		return fmt.Errorf("git checkout of %s failed: %w: %s", ref, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ReadFileAtBase returns a file's content on the PR's base branch in the cloned repo,
+// or the remote default branch when baseBranch is empty. found is false when the
+// file does not exist there.
+func ReadFileAtBase(ctx context.Context, repoPath, baseBranch, path string) (content []byte, found bool, err error) {
+	ref := "origin/HEAD"
+	if baseBranch != "" {
+		ref = "origin/" + baseBranch
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", repoPath, "show", ref+":"+path)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := stderr.String()
+		if strings.Contains(msg, "does not exist") || strings.Contains(msg, "exists on disk, but not in") {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("git show %s:%s failed: %w: %s", ref, path, err, strings.TrimSpace(msg))
+	}
+	return out, true, nil
+}
+
+// ListFilesAtBase returns the paths of all files on the PR's base branch in the cloned
+// repo, or on the remote default branch when baseBranch is empty.
+func ListFilesAtBase(ctx context.Context, repoPath, baseBranch string) ([]string, error) {
+	ref := "origin/HEAD"
+	if baseBranch != "" {
+		ref = "origin/" + baseBranch
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", repoPath, "ls-tree", "-r", "--name-only", ref).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git ls-tree %s failed: %w: %s", ref, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git ls-tree %s failed: %w", ref, err)
+	}
+	return strings.FieldsFunc(string(out), func(r rune) bool { return r == '\n' }), nil
+}
+
 func FormatArchitectureReviewComment(arch *models.ArchitectureReviewResponse) string {
 	var b strings.Builder
 
@@ -92,3 +159,68 @@ func cleanCommentBody(body string) string {
 
 	return strings.Join(cleanedLines, "\n")
 }
+
+// MatchPath reports whether a repository-relative file path matches a pattern. A
+// pattern such as ".go" matches by extension; otherwise it is a glob where "*" and
+// "?" stay within one path segment and "**" spans directories. Globs without a "/"
+// are matched against the file's base name as well. Callers matching many paths
+// should compile the pattern once with CompilePath.
+func MatchPath(pattern, filePath string) bool {
+	return CompilePath(pattern).Match(filePath)
+}
+
+// PathPattern is a compiled MatchPath pattern.
+type PathPattern struct {
+	pattern string
+	// ext is set for patterns that match by extension.
+	ext bool
+	// re is the compiled glob; nil when it does not compile, which matches nothing.
+	re *regexp.Regexp
+}
+
+// CompilePath compiles a pattern for MatchPath.
+func CompilePath(pattern string) *PathPattern {
+	p := &PathPattern{pattern: pattern}
+	if strings.HasPrefix(pattern, ".") && !strings.ContainsAny(pattern, "/*?[") {
+		p.ext = true
+	} else {
+		p.re, _ = regexp.Compile(globToRegexp(pattern))
+	}
+	return p
+}
+
+// Match reports whether a repository-relative file path matches the pattern.
+func (p *PathPattern) Match(filePath string) bool {
+	switch {
+	case p.ext:
+		return strings.HasSuffix(filePath, p.pattern)
+	case p.re == nil:
+		return false
+	case p.re.MatchString(filePath):
+		return true
+	}
+	return !strings.Contains(p.pattern, "/") && p.re.MatchString(path.Base(filePath))
+}
+
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
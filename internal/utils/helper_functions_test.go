@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{".go", "internal/service/review_service.go", true},
+		{".go", "config/config.yaml", false},
+		{"*.ts", "web/src/app.ts", true},
+		{"*.spec.ts", "web/src/app.ts", false},
+		{"migrations/*.sql", "migrations/001_init.sql", true},
+		{"migrations/*.sql", "db/migrations/001_init.sql", false},
+		{"**/migrations/*.sql", "db/migrations/001_init.sql", true},
+		{"**/migrations/*.sql", "migrations/001_init.sql", true},
+		{"docs/**", "docs/adr/0001.md", true},
+		{"vendor/**", "internal/vendor.go", false},
+		{"Dockerfile", "build/Dockerfile", true},
+		{"config.y?ml", "config/config.yaml", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchPath(tt.pattern, tt.path))
+		})
+	}
+}
+
+func TestReadFileAtBase(t *testing.T) {
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	origin := t.TempDir()
+	git(origin, "init", "--quiet", "--initial-branch=main")
+	assert.NoError(t, os.WriteFile(filepath.Join(origin, ".ai-review.yaml"), []byte("language: German\n"), 0o644))
+	git(origin, "add", "-A")
+	git(origin, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "init")
+	clone := filepath.Join(t.TempDir(), "clone")
+	git(origin, "clone", "--quiet", origin, clone)
+	// A PR head may change the file; the base version must still be read.
+	assert.NoError(t, os.WriteFile(filepath.Join(clone, ".ai-review.yaml"), []byte("language: French\n"), 0o644))
+
+	t.Run("Success - reads the base branch version", func(t *testing.T) {
+		content, found, err := ReadFileAtBase(context.Background(), clone, "main", ".ai-review.yaml")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "language: German\n", string(content))
+	})
+
+	t.Run("Success - falls back to the default branch", func(t *testing.T) {
+		_, found, err := ReadFileAtBase(context.Background(), clone, "", ".ai-review.yaml")
+		assert.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("Success - reports a missing file", func(t *testing.T) {
+		_, found, err := ReadFileAtBase(context.Background(), clone, "main", "missing.yaml")
+		assert.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Failure - unknown base branch", func(t *testing.T) {
+		_, _, err := ReadFileAtBase(context.Background(), clone, "nope", ".ai-review.yaml")
+		assert.Error(t, err)
+	})
+
+	t.Run("Success - lists the base branch files", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filepath.Join(clone, "untracked.md"), []byte("x"), 0o644))
+		files, err := ListFilesAtBase(context.Background(), clone, "main")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{".ai-review.yaml"}, files)
+	})
+
+	t.Run("Failure - lists an unknown base branch", func(t *testing.T) {
+		_, err := ListFilesAtBase(context.Background(), clone, "nope")
+		assert.Error(t, err)
+	})
+}